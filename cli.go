@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+	"github.com/snykk/kanban-app/service"
+)
+
+const commandUsage = `usage:
+  kanban-app                                        run the server
+  kanban-app export -user <id> [-o board.json]      write a user's board as JSON
+  kanban-app import -user <id> [-f board.json]      add an exported board to a user's board`
+
+// RunCommand runs a command line command against the configured database
+// and returns the process exit code.
+func RunCommand(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var run func(ctx context.Context, boardService service.BoardService, args []string, stdin io.Reader, stdout io.Writer) error
+	switch args[0] {
+	case "export":
+		run = exportCommand
+	case "import":
+		run = importCommand
+	default:
+		fmt.Fprintln(stderr, commandUsage)
+		return 2
+	}
+
+	err := repository.ConnectDB()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	boardService := service.NewBoardService(repository.NewBoardRepository(repository.GetDBConnection()))
+	err = run(context.Background(), boardService, args[1:], stdin, stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		if domainErr, ok := service.AsError(err); ok {
+			for _, field := range domainErr.Fields {
+				fmt.Fprintf(stderr, "  %s: %s\n", field.Field, field.Message)
+			}
+		}
+		return 1
+	}
+	return 0
+}
+
+func exportCommand(ctx context.Context, boardService service.BoardService, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	userId := flags.Int("user", 0, "id of the user whose board is exported")
+	output := flags.String("o", "", "file to write, standard output when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userId == 0 {
+		return errors.New("export: -user is required")
+	}
+
+	export, err := boardService.ExportBoard(ctx, *userId)
+	if err != nil {
+		return err
+	}
+
+	out := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+func importCommand(ctx context.Context, boardService service.BoardService, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	userId := flags.Int("user", 0, "id of the user the board is added to")
+	input := flags.String("f", "", "export file to read, standard input when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userId == 0 {
+		return errors.New("import: -user is required")
+	}
+
+	in := stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var export entity.BoardExport
+	decoder := json.NewDecoder(in)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&export); err != nil {
+		return fmt.Errorf("invalid board export: %w", err)
+	}
+
+	result, err := boardService.ImportBoard(ctx, *userId, export)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "imported %d categories, %d custom fields, %d tasks, %d comments and %d time entries\n",
+		result.Categories, result.CustomFields, result.Tasks, result.Comments, result.TimeEntries)
+	return nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/snykk/kanban-app/config"
+	"github.com/snykk/kanban-app/entity"
+)
+
+type CommentClient interface {
+	GetComments(taskId, userID string) ([]entity.Comment, error)
+	CreateComment(taskId, body, userID string) (respCode int, err error)
+}
+
+type commentClient struct {
+}
+
+func NewCommentClient() *commentClient {
+	return &commentClient{}
+}
+
+func (c *commentClient) GetComments(taskId, userID string) ([]entity.Comment, error) {
+	client, err := GetClientWithCookie(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", config.SetUrl("/api/v1/comments/get?task_id="+taskId), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, errors.New("status code not 200")
+	}
+
+	var comments []entity.Comment
+	err = json.NewDecoder(resp.Body).Decode(&comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (c *commentClient) CreateComment(taskId, body, userID string) (respCode int, err error) {
+	client, err := GetClientWithCookie(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	datajson := map[string]string{
+		"body": body,
+	}
+
+	b, err := json.Marshal(datajson)
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequest("POST", config.SetUrl("/api/v1/comments/create?task_id="+taskId), bytes.NewBuffer(b))
+	if err != nil {
+		return -1, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
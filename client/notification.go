@@ -0,0 +1,114 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/snykk/kanban-app/config"
+	"github.com/snykk/kanban-app/entity"
+)
+
+type NotificationClient interface {
+	GetNotifications(userID string) ([]entity.Notification, error)
+	GetUnreadCount(userID string) (int, error)
+	MarkAllRead(userID string) (respCode int, err error)
+}
+
+type notificationClient struct {
+}
+
+func NewNotificationClient() *notificationClient {
+	return &notificationClient{}
+}
+
+func (n *notificationClient) GetNotifications(userID string) ([]entity.Notification, error) {
+	client, err := GetClientWithCookie(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", config.SetUrl("/api/v1/notifications/get"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, errors.New("status code not 200")
+	}
+
+	var notifications []entity.Notification
+	err = json.NewDecoder(resp.Body).Decode(&notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (n *notificationClient) GetUnreadCount(userID string) (int, error) {
+	client, err := GetClientWithCookie(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("GET", config.SetUrl("/api/v1/notifications/count"), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return 0, errors.New("status code not 200")
+	}
+
+	var result struct {
+		Unread int `json:"unread"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Unread, nil
+}
+
+func (n *notificationClient) MarkAllRead(userID string) (respCode int, err error) {
+	client, err := GetClientWithCookie(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequest("PUT", config.SetUrl("/api/v1/notifications/read"), nil)
+	if err != nil {
+		return -1, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
@@ -3,17 +3,21 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/snykk/kanban-app/config"
 	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/utils"
 )
 
 type TaskClient interface {
-	CreateTask(title, description, category, userID string) (respCode int, err error)
+	CreateTask(title, description, estimate, category, userID string) (respCode int, err error)
 	GetTaskById(id, userID string) (entity.Task, error)
-	UpdateTask(id, title, description, userID string) (respCode int, err error)
+	SearchTasks(query, categoryId, userID string) (entity.TaskSearchResponse, error)
+	UpdateTask(id, title, description, estimate, version, userID string) (respCode int, err error)
 	UpdateCategoryTask(id, catId, userID string) (respCode int, err error)
 	DeleteTask(id, userID string) (respCode int, err error)
 }
@@ -25,7 +29,7 @@ func NewTaskClient() *taskClient {
 	return &taskClient{}
 }
 
-func (t *taskClient) CreateTask(title, description, category, userID string) (respCode int, err error) {
+func (t *taskClient) CreateTask(title, description, estimate, category, userID string) (respCode int, err error) {
 	client, err := GetClientWithCookie(userID)
 	if err != nil {
 		return -1, err
@@ -36,10 +40,16 @@ func (t *taskClient) CreateTask(title, description, category, userID string) (re
 		return -1, err
 	}
 
+	estimateFloat, err := parseEstimate(estimate)
+	if err != nil {
+		return -1, err
+	}
+
 	datajson := map[string]interface{}{
 		"title":       title,
 		"description": description,
 		"category_id": int(catId),
+		"estimate":    estimateFloat,
 	}
 
 	b, err := json.Marshal(datajson)
@@ -91,7 +101,45 @@ func (t *taskClient) GetTaskById(id, userID string) (entity.Task, error) {
 	return task, nil
 }
 
-func (t *taskClient) UpdateTask(id, title, description, userID string) (respCode int, err error) {
+func (t *taskClient) SearchTasks(query, categoryId, userID string) (entity.TaskSearchResponse, error) {
+	client, err := GetClientWithCookie(userID)
+	if err != nil {
+		return entity.TaskSearchResponse{}, err
+	}
+
+	params := url.Values{"q": {query}}
+	if categoryId != "" {
+		params.Set("category_id", categoryId)
+	}
+
+	req, err := http.NewRequest("GET", config.SetUrl("/api/v1/tasks/search?"+params.Encode()), nil)
+	if err != nil {
+		return entity.TaskSearchResponse{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return entity.TaskSearchResponse{}, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return entity.TaskSearchResponse{}, errors.New("status code not 200")
+	}
+
+	var results entity.TaskSearchResponse
+	err = json.NewDecoder(resp.Body).Decode(&results)
+	if err != nil {
+		return entity.TaskSearchResponse{}, err
+	}
+
+	return results, nil
+}
+
+// UpdateTask sends version as If-Match so edits made against a stale task are
+// rejected with 412. An empty version skips the check.
+func (t *taskClient) UpdateTask(id, title, description, estimate, version, userID string) (respCode int, err error) {
 	client, err := GetClientWithCookie(userID)
 	if err != nil {
 		return -1, err
@@ -102,10 +150,16 @@ func (t *taskClient) UpdateTask(id, title, description, userID string) (respCode
 		return -1, err
 	}
 
+	estimateFloat, err := parseEstimate(estimate)
+	if err != nil {
+		return -1, err
+	}
+
 	datajson := map[string]interface{}{
 		"id":          int(taskId),
 		"title":       title,
 		"description": description,
+		"estimate":    estimateFloat,
 	}
 
 	b, err := json.Marshal(datajson)
@@ -119,6 +173,13 @@ func (t *taskClient) UpdateTask(id, title, description, userID string) (respCode
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if version != "" {
+		versionInt, err := strconv.Atoi(version)
+		if err != nil {
+			return -1, err
+		}
+		req.Header.Set("If-Match", utils.ETag(versionInt))
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -193,3 +254,11 @@ func (t *taskClient) DeleteTask(id, userID string) (respCode int, err error) {
 
 	return resp.StatusCode, nil
 }
+
+func parseEstimate(estimate string) (float64, error) {
+	if estimate == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(estimate, 64)
+}
@@ -11,6 +11,7 @@ var AppConfig Config
 
 type Config struct {
 	Port        int
+	GRPCPort    int
 	Environment string
 	Debug       bool
 	BaseURL     string
@@ -32,6 +33,10 @@ type Config struct {
 	REDISHost     string
 	REDISPassword string
 	REDISExpired  int
+
+	IdempotencyWindowHours      int
+	WebhookRetryBaseSeconds     int
+	WebhookAllowPrivateNetworks bool
 }
 
 var ERRORS_EMPTY_ENV = errors.New("required variabel environment is empty")
@@ -48,6 +53,7 @@ func InitializeAppConfig() error {
 
 	// assign value
 	AppConfig.Port = viper.GetInt("PORT")
+	AppConfig.GRPCPort = viper.GetInt("GRPC_PORT")
 	AppConfig.Environment = viper.GetString("ENVIRONMENT")
 	AppConfig.Debug = viper.GetBool("DEBUG")
 	AppConfig.BaseURL = viper.GetString("BASE_URL")
@@ -59,6 +65,10 @@ func InitializeAppConfig() error {
 	AppConfig.DBPassword = viper.GetString("DB_PASSWORD")
 	AppConfig.DBDsn = viper.GetString("DB_DSN")
 
+	AppConfig.IdempotencyWindowHours = viper.GetInt("IDEMPOTENCY_WINDOW_HOURS")
+	AppConfig.WebhookRetryBaseSeconds = viper.GetInt("WEBHOOK_RETRY_BASE_SECONDS")
+	AppConfig.WebhookAllowPrivateNetworks = viper.GetBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS")
+
 	// check
 	if AppConfig.Port == 0 || AppConfig.Environment == "" || AppConfig.BaseURL == "" {
 		return ERRORS_EMPTY_ENV
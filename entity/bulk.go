@@ -0,0 +1,44 @@
+package entity
+
+const (
+	BulkActionMove     = "move"
+	BulkActionDelete   = "delete"
+	BulkActionArchive  = "archive"
+	BulkActionAddLabel = "add_label"
+	BulkActionAssign   = "assign"
+)
+
+const (
+	BulkStatusOK       = "ok"
+	BulkStatusNotFound = "not_found"
+	BulkStatusSkipped  = "skipped"
+)
+
+const (
+	MaxBulkTasks   = 100
+	MaxLabelLength = 50
+)
+
+// BulkTaskRequest limits the ids to MaxBulkTasks and the label to
+// MaxLabelLength.
+type BulkTaskRequest struct {
+	TaskIDs    []int  `json:"task_ids" binding:"required,max=100"`
+	Action     string `json:"action" binding:"required"`
+	CategoryID int    `json:"category_id" binding:"min=0"`
+	Label      string `json:"label" binding:"max=50"`
+	AssigneeID int    `json:"assignee_id" binding:"min=0"`
+}
+
+type BulkTaskResult struct {
+	TaskID int    `json:"task_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkTaskResponse reports the outcome per task. The action is applied to
+// every task or, when any of them is rejected, to none.
+type BulkTaskResponse struct {
+	Action  string           `json:"action"`
+	Applied bool             `json:"applied"`
+	Results []BulkTaskResult `json:"results"`
+}
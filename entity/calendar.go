@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Kinds of components the iCalendar feed lists tasks as.
+const (
+	CalendarEvents = "event"
+	CalendarTodos  = "todo"
+)
+
+// CalendarFeed holds the secret token of a user's iCalendar feed. Anyone
+// with the token can read the feed, so regenerating it revokes the old URL.
+type CalendarFeed struct {
+	ID        int       `gorm:"primaryKey" json:"-"`
+	UserID    int       `json:"user_id" gorm:"type:int;not null;uniqueIndex"`
+	Token     string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CalendarFeedResponse struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CalendarFilter narrows the tasks of a feed. BoardID, when set, must be
+// the board of the feed's owner. Type is CalendarEvents or CalendarTodos.
+type CalendarFilter struct {
+	BoardID    int
+	CategoryID int
+	Label      string
+	Type       string
+}
@@ -6,38 +6,59 @@ type Category struct {
 	ID        int       `gorm:"primaryKey" json:"id"`
 	Type      string    `json:"type" gorm:"type:varchar(255);not null"`
 	UserID    int       `json:"user_id"`
+	Version   int       `json:"version" gorm:"not null;default:1"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type CategoryRequest struct {
-	Type string `json:"type" binding:"required"`
+	Type string `json:"type" binding:"required,max=255"`
+}
+
+// CategoryPatch holds the members of a JSON Merge Patch on a category.
+type CategoryPatch struct {
+	Type *string
+	// Version is the version the patch was made against, 0 skips the check.
+	Version int
 }
 
 type CategoryData struct {
-	ID    int    `json:"id"`
-	Type  string `json:"type"`
-	Tasks []Task `json:"tasks"`
+	ID            int     `json:"id"`
+	Type          string  `json:"type"`
+	Tasks         []Task  `json:"tasks"`
+	TotalEstimate float64 `json:"total_estimate"`
+	BoardEstimate float64 `json:"board_estimate"`
 }
 
 func DataToCategoryData(categories []Category, tasks []Task) []CategoryData {
 	var categoryData []CategoryData
+	var boardEstimate float64
 
 	for _, category := range categories {
 		var tasksData []Task
+		var totalEstimate float64
 
 		for _, task := range tasks {
 			if task.CategoryID == category.ID {
 				tasksData = append(tasksData, task)
+				totalEstimate += task.Estimate
 			}
 		}
 
+		boardEstimate += totalEstimate
 		categoryData = append(categoryData, CategoryData{
-			ID:    category.ID,
-			Type:  category.Type,
-			Tasks: tasksData,
+			ID:            category.ID,
+			Type:          category.Type,
+			Tasks:         tasksData,
+			TotalEstimate: totalEstimate,
 		})
 	}
 
+	// every column carries the board total so the dashboard can show it
+	// without summing again
+	for i := range categoryData {
+		categoryData[i].BoardEstimate = boardEstimate
+	}
+
 	return categoryData
 }
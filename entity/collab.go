@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Messages of the collaboration channel. Clients send edit, stop_edit and
+// ping, the server answers with presence, event, pong and error.
+const (
+	CollabPresence = "presence"
+	CollabEvent    = "event"
+	CollabEdit     = "edit"
+	CollabStopEdit = "stop_edit"
+	CollabPing     = "ping"
+	CollabPong     = "pong"
+	CollabError    = "error"
+)
+
+type CollabMessage struct {
+	Type     string           `json:"type"`
+	TaskID   int              `json:"task_id,omitempty"`
+	ClientID int64            `json:"client_id,omitempty"`
+	Presence []PresenceClient `json:"presence,omitempty"`
+	Event    *BoardEvent      `json:"event,omitempty"`
+	Error    *Problem         `json:"error,omitempty"`
+}
+
+// PresenceClient is one connection on a board. EditingTaskID is the soft
+// lock the client holds, if any.
+type PresenceClient struct {
+	ClientID      int64     `json:"client_id"`
+	UserID        int       `json:"user_id"`
+	Fullname      string    `json:"fullname"`
+	EditingTaskID int       `json:"editing_task_id,omitempty"`
+	JoinedAt      time.Time `json:"joined_at"`
+}
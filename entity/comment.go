@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+type Comment struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	TaskID    int       `json:"task_id" gorm:"type:int;not null;index"`
+	UserID    int       `json:"user_id" gorm:"type:int;not null"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	Mentions  []Mention `json:"mentions,omitempty" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	CustomFieldText     = "text"
+	CustomFieldNumber   = "number"
+	CustomFieldDate     = "date"
+	CustomFieldSelect   = "select"
+	CustomFieldCheckbox = "checkbox"
+)
+
+// CustomField is a field definition on a user's board. Every task on the
+// board may carry one value per field.
+type CustomField struct {
+	ID        int            `gorm:"primaryKey" json:"id"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
+	Type      string         `json:"type" gorm:"type:varchar(20);not null"`
+	Options   pq.StringArray `json:"options" gorm:"type:text[]"`
+	UserID    int            `json:"user_id" gorm:"type:int;not null;index"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type CustomFieldRequest struct {
+	Name    string   `json:"name" binding:"required,max=255"`
+	Type    string   `json:"type" binding:"required"`
+	Options []string `json:"options"`
+}
+
+func IsValidCustomFieldType(fieldType string) bool {
+	switch fieldType {
+	case CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldSelect, CustomFieldCheckbox:
+		return true
+	}
+	return false
+}
+
+// ValueColumn returns the custom_field_values column holding values of
+// this field's type.
+func (f CustomField) ValueColumn() string {
+	switch f.Type {
+	case CustomFieldNumber:
+		return "number_value"
+	case CustomFieldDate:
+		return "date_value"
+	case CustomFieldCheckbox:
+		return "bool_value"
+	default:
+		return "text_value"
+	}
+}
+
+// CustomFieldValue stores a task's value in the column matching the field
+// type. Name and Value are the API representation and are not persisted.
+type CustomFieldValue struct {
+	ID          int         `gorm:"primaryKey" json:"-"`
+	TaskID      int         `json:"-" gorm:"type:int;not null;uniqueIndex:idx_custom_field_values_task_field"`
+	FieldID     int         `json:"field_id" gorm:"type:int;not null;uniqueIndex:idx_custom_field_values_task_field"`
+	TextValue   *string     `json:"-" gorm:"type:varchar(255)"`
+	NumberValue *float64    `json:"-"`
+	DateValue   *time.Time  `json:"-" gorm:"type:date"`
+	BoolValue   *bool       `json:"-"`
+	Name        string      `json:"name" gorm:"-"`
+	Value       interface{} `json:"value" gorm:"-"`
+}
+
+// SetDisplay fills Name and Value from the field definition and the typed
+// column.
+func (v *CustomFieldValue) SetDisplay(field CustomField) {
+	v.Name = field.Name
+	v.Value = nil
+
+	switch {
+	case v.NumberValue != nil:
+		v.Value = *v.NumberValue
+	case v.DateValue != nil:
+		v.Value = v.DateValue.Format(DateLayout)
+	case v.BoolValue != nil:
+		v.Value = *v.BoolValue
+	case v.TextValue != nil:
+		v.Value = *v.TextValue
+	}
+}
+
+type CustomFieldFilter struct {
+	FieldID    int
+	Column     string
+	Value      interface{}
+	SortID     int
+	SortColumn string
+}
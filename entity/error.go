@@ -1,5 +1,12 @@
 package entity
 
+import (
+	"net/http"
+	"strings"
+)
+
+const ProblemContentType = "application/problem+json"
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -9,3 +16,36 @@ func NewErrorResponse(msg string) ErrorResponse {
 		Error: msg,
 	}
 }
+
+// Problem is an RFC 7807 problem details body. Code is a stable machine
+// readable identifier, Error repeats Detail for clients reading the older
+// ErrorResponse shape.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Code   string       `json:"code"`
+	Error  string       `json:"error"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// NewProblem builds a problem whose code is derived from the status, e.g.
+// "bad_request" for 400.
+func NewProblem(status int, detail string) Problem {
+	title := http.StatusText(status)
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   strings.ReplaceAll(strings.ToLower(title), " ", "_"),
+		Error:  detail,
+	}
+}
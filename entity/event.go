@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	EventTaskCreated     = "task.created"
+	EventTaskUpdated     = "task.updated"
+	EventTaskMoved       = "task.moved"
+	EventTaskDeleted     = "task.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+// BoardEvent describes a change on a board. A board is identified by its
+// owner's user id. Task and Category hold the state after the change and
+// are left empty for deletes.
+type BoardEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	BoardID    int       `json:"board_id"`
+	ActorID    int       `json:"actor_id"`
+	TaskID     int       `json:"task_id,omitempty"`
+	CategoryID int       `json:"category_id,omitempty"`
+	Task       *Task     `json:"task,omitempty"`
+	Category   *Category `json:"category,omitempty"`
+	At         time.Time `json:"at"`
+}
@@ -0,0 +1,95 @@
+package entity
+
+import "time"
+
+// BoardExportVersion is the version written by the board export. Imports
+// accept this version only.
+const BoardExportVersion = 1
+
+// BoardExport is the portable form of a board. Ids are the ones of the
+// exporting instance and are only used to link the records of the file,
+// an import creates everything with new ids. Users are not part of the
+// export, so assignees are dropped and comments are recreated as written
+// by the importing user.
+type BoardExport struct {
+	Version      int                 `json:"version"`
+	ExportedAt   time.Time           `json:"exported_at"`
+	Categories   []ExportCategory    `json:"categories"`
+	CustomFields []ExportCustomField `json:"custom_fields"`
+	Tasks        []ExportTask        `json:"tasks"`
+}
+
+type ExportCategory struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+}
+
+type ExportCustomField struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Options []string `json:"options,omitempty"`
+}
+
+type ExportTask struct {
+	ID           int                `json:"id"`
+	CategoryID   int                `json:"category_id"`
+	Title        string             `json:"title"`
+	Description  string             `json:"description"`
+	Estimate     float64            `json:"estimate"`
+	Labels       []string           `json:"labels"`
+	DueDate      *time.Time         `json:"due_date,omitempty"`
+	ArchivedAt   *time.Time         `json:"archived_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	CustomFields []ExportFieldValue `json:"custom_fields,omitempty"`
+	Comments     []ExportComment    `json:"comments,omitempty"`
+	TimeEntries  []ExportTimeEntry  `json:"time_entries,omitempty"`
+}
+
+// ExportFieldValue holds the value of a custom field in its API form, see
+// CustomFieldValue.SetDisplay.
+type ExportFieldValue struct {
+	FieldID int         `json:"field_id"`
+	Value   interface{} `json:"value"`
+}
+
+type ExportComment struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportTimeEntry only holds finished entries, a running timer is not
+// exported. Duration is informational, an import derives it from the
+// start and end.
+type ExportTimeEntry struct {
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Duration  int64     `json:"duration"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// BoardSnapshot is the stored form of a board, as read for an export or
+// written by an import. On import the ids are those of the file and the
+// repository maps them to the new rows.
+type BoardSnapshot struct {
+	Categories   []Category
+	CustomFields []CustomField
+	Tasks        []Task
+	Values       []CustomFieldValue
+	Comments     []Comment
+	TimeEntries  []TimeEntry
+}
+
+// BoardImportResult counts what an import created and maps the ids of the
+// file to the new ones.
+type BoardImportResult struct {
+	Categories     int         `json:"categories"`
+	CustomFields   int         `json:"custom_fields"`
+	Tasks          int         `json:"tasks"`
+	Comments       int         `json:"comments"`
+	TimeEntries    int         `json:"time_entries"`
+	CategoryIDs    map[int]int `json:"category_ids"`
+	CustomFieldIDs map[int]int `json:"custom_field_ids"`
+	TaskIDs        map[int]int `json:"task_ids"`
+}
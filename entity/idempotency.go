@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const MaxIdempotencyKeyLength = 255
+
+// DefaultIdempotencyWindow is how long keys are kept when
+// IDEMPOTENCY_WINDOW_HOURS is not set.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// IdempotencyLease is how long a request may hold its key. A retry after
+// the lease takes the key over, so a request lost to a crash does not block
+// the key until it expires.
+const IdempotencyLease = time.Minute
+
+// IdempotencyKey remembers the response to a create request so a retry with
+// the same key replays it. A StatusCode of 0 marks a request in progress.
+// ResponseHeader holds the replayed headers as "Name: value" lines.
+type IdempotencyKey struct {
+	ID             int            `gorm:"primaryKey" json:"id"`
+	UserID         int            `json:"user_id" gorm:"type:int;not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Key            string         `json:"key" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	RequestHash    string         `json:"request_hash" gorm:"type:char(64);not null"`
+	StatusCode     int            `json:"status_code" gorm:"type:int;not null;default:0"`
+	ResponseHeader pq.StringArray `json:"-" gorm:"type:text[]"`
+	ResponseBody   []byte         `json:"-" gorm:"type:bytea"`
+	LockedUntil    time.Time      `json:"locked_until" gorm:"not null;default:now()"`
+	ExpiresAt      time.Time      `json:"expires_at" gorm:"not null;index"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// Groupings of the tasks of a column in the Markdown export.
+const (
+	MarkdownGroupLabel    = "label"
+	MarkdownGroupAssignee = "assignee"
+)
+
+// DoneCategory is the name of the category whose tasks count as completed,
+// as created for every new user.
+const DoneCategory = "Done"
+
+// MarkdownOptions selects what the Markdown export renders: the whole
+// board, the column CategoryID, or with Completed the tasks of the done
+// column last updated between From and To. Zero dates leave the range open.
+type MarkdownOptions struct {
+	CategoryID int
+	Completed  bool
+	From       time.Time
+	To         time.Time
+	GroupBy    string
+}
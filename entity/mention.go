@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Mention records a user referenced as @email or @handle in a task
+// description (CommentID 0) or in a comment. Email is read through a join
+// with users and is not a column of its own.
+type Mention struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	TaskID    int       `json:"task_id" gorm:"type:int;not null;index"`
+	CommentID int       `json:"comment_id" gorm:"type:int;not null;default:0"`
+	UserID    int       `json:"user_id" gorm:"type:int;not null"`
+	ActorID   int       `json:"actor_id" gorm:"type:int;not null"`
+	Token     string    `json:"token" gorm:"type:varchar(255);not null"`
+	Email     string    `json:"email" gorm:"->;-:migration"`
+	CreatedAt time.Time `json:"created_at"`
+}
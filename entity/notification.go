@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+const (
+	NotificationEdited    = "edited"
+	NotificationMoved     = "moved"
+	NotificationCommented = "commented"
+	NotificationAssigned  = "assigned"
+	NotificationMentioned = "mentioned"
+)
+
+type Watcher struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	TaskID    int       `json:"task_id" gorm:"type:int;not null;uniqueIndex:idx_watchers_task_user"`
+	UserID    int       `json:"user_id" gorm:"type:int;not null;uniqueIndex:idx_watchers_task_user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Notification struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	UserID    int        `json:"user_id" gorm:"type:int;not null;index"`
+	ActorID   int        `json:"actor_id" gorm:"type:int;not null"`
+	TaskID    int        `json:"task_id" gorm:"type:int;not null"`
+	Type      string     `json:"type" gorm:"type:varchar(20);not null"`
+	Message   string     `json:"message" gorm:"type:text;not null"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationReadRequest struct {
+	IDs []int `json:"ids"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+const MaxSearchResults = 50
+
+type TaskSearchFilter struct {
+	Query      string
+	CategoryID int
+	Limit      int
+}
+
+type TaskSearchResult struct {
+	TaskID       int     `json:"task_id"`
+	Title        string  `json:"title"`
+	CategoryID   int     `json:"category_id"`
+	CategoryType string  `json:"category_type"`
+	Rank         float64 `json:"rank"`
+	// Snippet wraps the matched terms in <mark> tags; the rest of the
+	// text is not escaped.
+	Snippet string `json:"snippet"`
+}
+
+type TaskSearchResponse struct {
+	Query   string             `json:"query"`
+	Results []TaskSearchResult `json:"results"`
+}
@@ -1,26 +1,128 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MaxTaskEstimate is the upper bound accepted for a task estimate, either in
+// story points or hours.
+const MaxTaskEstimate = 1000
+
+func IsValidEstimate(estimate float64) bool {
+	return estimate >= 0 && estimate <= MaxTaskEstimate
+}
 
 type Task struct {
-	ID          int       `gorm:"primaryKey" json:"id"`
-	Title       string    `json:"title" gorm:"type:varchar(255);not null"`
-	Description string    `json:"description" gorm:"type:text;not null"`
-	CategoryID  int       `json:"category_id" gorm:"type:int;not null"`
-	UserID      int       `json:"user_id" gorm:"type:int;not null"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	DeletedAt   time.Time `json:"deleted_at"`
+	ID           int                `gorm:"primaryKey" json:"id"`
+	Title        string             `json:"title" gorm:"type:varchar(255);not null"`
+	Description  string             `json:"description" gorm:"type:text;not null"`
+	CategoryID   int                `json:"category_id" gorm:"type:int;not null"`
+	UserID       int                `json:"user_id" gorm:"type:int;not null"`
+	Estimate     float64            `json:"estimate" gorm:"type:numeric(6,2);not null;default:0"`
+	Labels       pq.StringArray     `json:"labels" gorm:"type:text[];not null;default:'{}'"`
+	DueDate      *time.Time         `json:"due_date" gorm:"type:date;index"`
+	AssigneeID   *int               `json:"assignee_id" gorm:"index"`
+	ArchivedAt   *time.Time         `json:"archived_at"`
+	CustomFields []CustomFieldValue `json:"custom_fields,omitempty" gorm:"-"`
+	Mentions     []Mention          `json:"mentions,omitempty" gorm:"-"`
+	Version      int                `json:"version" gorm:"not null;default:1"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	DeletedAt    time.Time          `json:"deleted_at"`
 }
 
+// TaskRequest bounds the estimate by MaxTaskEstimate. DueDate is a date
+// as YYYY-MM-DD.
 type TaskRequest struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
-	CategoryID  int    `json:"category_id"`
+	ID           int                `json:"id"`
+	Title        string             `json:"title" binding:"required,max=255"`
+	Description  string             `json:"description" binding:"required"`
+	CategoryID   int                `json:"category_id" binding:"min=0"`
+	Estimate     float64            `json:"estimate" binding:"estimate"`
+	DueDate      string             `json:"due_date"`
+	CustomFields []CustomFieldValue `json:"custom_fields"`
+}
+
+// TaskPatch holds the members of a JSON Merge Patch (RFC 7396) on a task.
+// Nil fields were absent from the document; nullable fields set to null
+// arrive as their zero value.
+type TaskPatch struct {
+	Title        *string
+	Description  *string
+	CategoryID   *int
+	Estimate     *float64
+	DueDate      *time.Time
+	CustomFields []CustomFieldValue
+	// Version is the version the patch was made against, 0 skips the check.
+	Version int
+}
+
+const (
+	DefaultTaskPerPage = 20
+	MaxTaskPerPage     = 100
+)
+
+// TaskSortColumns maps the sort keys accepted by the task listing to their
+// columns.
+var TaskSortColumns = map[string]string{
+	"id":         "tasks.id",
+	"title":      "tasks.title",
+	"estimate":   "tasks.estimate",
+	"due_date":   "tasks.due_date",
+	"created_at": "tasks.created_at",
+	"updated_at": "tasks.updated_at",
+}
+
+type TaskFilter struct {
+	// Archived lists archived tasks instead of the active ones.
+	Archived    bool
+	CategoryID  int
+	Query       string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	CustomField CustomFieldFilter
+	Sort        string
+	SortDesc    bool
+	Page        int
+	PerPage     int
+	// Label keeps the tasks carrying the label, HasDueDate those with a
+	// due date.
+	Label      string
+	HasDueDate bool
+}
+
+type TaskPage struct {
+	Data       []Task `json:"data"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	TotalPages int    `json:"total_pages"`
+}
+
+func NewTaskPage(tasks []Task, total int64, filter TaskFilter) TaskPage {
+	if tasks == nil {
+		tasks = []Task{}
+	}
+
+	totalPages := int(total) / filter.PerPage
+	if int(total)%filter.PerPage != 0 {
+		totalPages++
+	}
+
+	return TaskPage{
+		Data:       tasks,
+		Total:      total,
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+		TotalPages: totalPages,
+	}
 }
 
 type TaskCategoryRequest struct {
 	ID         int `json:"id"`
-	CategoryID int `json:"category_id" binding:"required"`
+	CategoryID int `json:"category_id" binding:"required,min=1"`
 }
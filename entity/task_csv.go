@@ -0,0 +1,38 @@
+package entity
+
+// TaskCSVColumns lists the columns of the task CSV export in their default
+// order. Times are written in RFC 3339, the due date as YYYY-MM-DD.
+var TaskCSVColumns = []string{"id", "title", "description", "category", "category_id", "estimate", "labels", "due_date", "assignee_id", "archived_at", "created_at", "updated_at"}
+
+// TaskCSVImportFields lists the task fields a CSV import can read.
+var TaskCSVImportFields = []string{"title", "description", "category", "estimate", "labels", "due_date"}
+
+// CSVLabelSeparator separates the labels of a task within one CSV cell.
+const CSVLabelSeparator = ";"
+
+// MaxTaskCSVRows bounds the rows of a CSV import, the header excluded.
+const MaxTaskCSVRows = 5000
+
+// TaskCSVExportOptions selects the columns and the tasks of a CSV export.
+// The page of the filter is ignored, every matching task is exported.
+type TaskCSVExportOptions struct {
+	Columns []string
+	Filter  TaskFilter
+}
+
+// TaskCSVImportRequest holds a CSV file with a header row. Mapping maps the
+// task fields of TaskCSVImportFields to header names, title is required.
+// Rows without a category go to CategoryID, and categories the board does
+// not have are created only with CreateCategories.
+type TaskCSVImportRequest struct {
+	CSV              string            `json:"csv" binding:"required"`
+	Mapping          map[string]string `json:"mapping" binding:"required"`
+	CategoryID       int               `json:"category_id" binding:"min=0"`
+	CreateCategories bool              `json:"create_categories"`
+}
+
+type TaskCSVImportResult struct {
+	Tasks             int        `json:"tasks"`
+	TaskIDs           []int      `json:"task_ids"`
+	CreatedCategories []Category `json:"created_categories"`
+}
@@ -0,0 +1,66 @@
+package entity
+
+import "time"
+
+const DateLayout = "2006-01-02"
+
+type TimeEntry struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	TaskID    int        `json:"task_id" gorm:"type:int;not null;index"`
+	UserID    int        `json:"user_id" gorm:"type:int;not null;uniqueIndex:idx_time_entries_running,where:ended_at IS NULL"`
+	StartedAt time.Time  `json:"started_at" gorm:"not null"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Duration  int64      `json:"duration" gorm:"not null;default:0"`
+	Note      string     `json:"note" gorm:"type:varchar(255)"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type TimeEntryRequest struct {
+	TaskID    int       `json:"task_id" binding:"required,min=1"`
+	StartedAt time.Time `json:"started_at" binding:"required"`
+	EndedAt   time.Time `json:"ended_at" binding:"required"`
+	Note      string    `json:"note" binding:"max=255"`
+}
+
+type TimeReportFilter struct {
+	TaskID int
+	UserID int
+	From   time.Time
+	To     time.Time
+}
+
+type TimeReportItem struct {
+	TaskID    int    `json:"task_id"`
+	TaskTitle string `json:"task_title"`
+	UserID    int    `json:"user_id"`
+	Date      string `json:"date"`
+	Seconds   int64  `json:"seconds"`
+}
+
+type TimeReport struct {
+	From         string           `json:"from,omitempty"`
+	To           string           `json:"to,omitempty"`
+	TotalSeconds int64            `json:"total_seconds"`
+	Items        []TimeReportItem `json:"items"`
+}
+
+func NewTimeReport(filter TimeReportFilter, items []TimeReportItem) TimeReport {
+	report := TimeReport{Items: items}
+	if report.Items == nil {
+		report.Items = []TimeReportItem{}
+	}
+
+	if !filter.From.IsZero() {
+		report.From = filter.From.Format(DateLayout)
+	}
+	if !filter.To.IsZero() {
+		report.To = filter.To.Format(DateLayout)
+	}
+
+	for _, item := range items {
+		report.TotalSeconds += item.Seconds
+	}
+
+	return report
+}
@@ -0,0 +1,85 @@
+package entity
+
+import "time"
+
+// TrelloBoard is the part of a Trello board export ("Export as JSON" in the
+// board menu) that the importer reads. Other members are ignored.
+type TrelloBoard struct {
+	Name       string            `json:"name"`
+	Lists      []TrelloList      `json:"lists"`
+	Cards      []TrelloCard      `json:"cards"`
+	Checklists []TrelloChecklist `json:"checklists"`
+	Actions    []TrelloAction    `json:"actions"`
+}
+
+type TrelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type TrelloCard struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name"`
+	Desc             string        `json:"desc"`
+	IDList           string        `json:"idList"`
+	Closed           bool          `json:"closed"`
+	Due              *time.Time    `json:"due"`
+	Pos              float64       `json:"pos"`
+	Labels           []TrelloLabel `json:"labels"`
+	DateLastActivity time.Time     `json:"dateLastActivity"`
+}
+
+type TrelloLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TrelloChecklist struct {
+	ID         string            `json:"id"`
+	IDCard     string            `json:"idCard"`
+	Name       string            `json:"name"`
+	Pos        float64           `json:"pos"`
+	CheckItems []TrelloCheckItem `json:"checkItems"`
+}
+
+type TrelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// TrelloAction is an entry of the board's activity. Only comments
+// ("commentCard") are imported.
+type TrelloAction struct {
+	Type string    `json:"type"`
+	Date time.Time `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+		Card struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+}
+
+// TrelloImportReport describes what a Trello import creates. A dry run
+// stops before anything is written and has no Result.
+type TrelloImportReport struct {
+	DryRun         bool                 `json:"dry_run"`
+	Categories     []TrelloImportColumn `json:"categories"`
+	Tasks          int                  `json:"tasks"`
+	ArchivedTasks  int                  `json:"archived_tasks"`
+	Comments       int                  `json:"comments"`
+	ChecklistItems int                  `json:"checklist_items"`
+	DueDates       int                  `json:"due_dates"`
+	Warnings       []string             `json:"warnings"`
+	Result         *BoardImportResult   `json:"result,omitempty"`
+}
+
+// TrelloImportColumn is a Trello list and the titles of the tasks made from
+// its cards.
+type TrelloImportColumn struct {
+	Name  string   `json:"name"`
+	Tasks []string `json:"tasks"`
+}
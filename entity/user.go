@@ -12,12 +12,13 @@ type User struct {
 }
 
 type UserLogin struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,maxbytes=72"`
 }
 
+// UserRegister caps the password at 72 bytes, the most bcrypt reads.
 type UserRegister struct {
-	Fullname string `json:"fullname" binding:"required"`
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Fullname string `json:"fullname" binding:"required,max=255"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=6,maxbytes=72"`
 }
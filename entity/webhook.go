@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	WebhookEventHeader     = "X-Kanban-Event"
+	WebhookDeliveryHeader  = "X-Kanban-Delivery"
+	WebhookSignatureHeader = "X-Kanban-Signature-256"
+)
+
+// WebhookAllEvents subscribes a webhook to every board event.
+const WebhookAllEvents = "*"
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryInFlight  = "delivering"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// MaxWebhookAttempts is how often a delivery is tried before it is marked
+// failed.
+const MaxWebhookAttempts = 6
+
+// BoardEventTypes lists the event types a webhook may subscribe to.
+var BoardEventTypes = []string{
+	EventTaskCreated, EventTaskUpdated, EventTaskMoved, EventTaskDeleted,
+	EventCategoryCreated, EventCategoryUpdated, EventCategoryDeleted,
+}
+
+// Webhook posts the events of a user's board to URL. Every body is signed
+// with an HMAC-SHA256 of Secret, sent as "sha256=<hex>" in
+// WebhookSignatureHeader.
+type Webhook struct {
+	ID        int            `gorm:"primaryKey" json:"id"`
+	UserID    int            `json:"user_id" gorm:"type:int;not null;index"`
+	URL       string         `json:"url" gorm:"type:varchar(2048);not null"`
+	Secret    string         `json:"-" gorm:"type:varchar(255);not null"`
+	Events    pq.StringArray `json:"events" gorm:"type:text[];not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Secret string   `json:"secret" binding:"required,min=16,max=255"`
+	Events []string `json:"events" binding:"required"`
+}
+
+// Subscribes reports whether the webhook wants events of the given type.
+func (w Webhook) Subscribes(eventType string) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == WebhookAllEvents || subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one webhook, with the outcome of its
+// last attempt. Only the status code of a response is kept, never its body.
+// Pending deliveries are retried at NextAttemptAt.
+type WebhookDelivery struct {
+	ID            int        `gorm:"primaryKey" json:"id"`
+	WebhookID     int        `json:"webhook_id" gorm:"type:int;not null;index"`
+	EventID       int64      `json:"event_id" gorm:"not null"`
+	EventType     string     `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload       []byte     `json:"-" gorm:"type:bytea;not null"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due"`
+	Attempts      int        `json:"attempts" gorm:"type:int;not null;default:0"`
+	StatusCode    int        `json:"status_code" gorm:"type:int;not null;default:0"`
+	Error         string     `json:"error" gorm:"type:text"`
+	RedeliveryOf  *int       `json:"redelivery_of"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// DefaultWebhookRetryBase is the wait before the first retry when
+// WEBHOOK_RETRY_BASE_SECONDS is not set. Every further retry waits twice as
+// long as the one before.
+const DefaultWebhookRetryBase = 10 * time.Second
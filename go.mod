@@ -11,7 +11,11 @@ require (
 )
 
 require (
+	github.com/graphql-go/graphql v0.8.1
 	github.com/jackc/pgx/v4 v4.17.2
 	github.com/spf13/viper v1.14.0
 	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa
+	golang.org/x/net v0.0.0-20221014081412-f15817d10f9b
+	google.golang.org/grpc v1.50.1
+	google.golang.org/protobuf v1.28.1
 )
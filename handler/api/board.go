@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// maxBoardImportSize bounds the board import body. Trello exports carry
+// the board's activity and get larger.
+const (
+	maxBoardImportSize  = 10 << 20
+	maxTrelloImportSize = 50 << 20
+)
+
+type BoardAPI interface {
+	ExportBoard(w http.ResponseWriter, r *http.Request)
+	ImportBoard(w http.ResponseWriter, r *http.Request)
+	ImportTrello(w http.ResponseWriter, r *http.Request)
+}
+
+type boardAPI struct {
+	boardService service.BoardService
+}
+
+func NewBoardAPI(boardService service.BoardService) *boardAPI {
+	return &boardAPI{boardService}
+}
+
+func (b *boardAPI) ExportBoard(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	export, err := b.boardService.ExportBoard(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("board-%d-%s.json", userIdInt, export.ExportedAt.Format(entity.DateLayout))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(export)
+}
+
+func (b *boardAPI) ImportBoard(w http.ResponseWriter, r *http.Request) {
+	var export entity.BoardExport
+
+	// unknown members mean the file is not an export of this version
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBoardImportSize))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&export)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid board export: "+err.Error())
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	result, err := b.boardService.ImportBoard(r.Context(), userIdInt, export)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (b *boardAPI) ImportTrello(w http.ResponseWriter, r *http.Request) {
+	var board entity.TrelloBoard
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTrelloImportSize)).Decode(&board)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid trello board: "+err.Error())
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := b.boardService.ImportTrello(r.Context(), userIdInt, board, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if dryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type CalendarAPI interface {
+	GetCalendarFeed(w http.ResponseWriter, r *http.Request)
+	RegenerateCalendarToken(w http.ResponseWriter, r *http.Request)
+	DeleteCalendarFeed(w http.ResponseWriter, r *http.Request)
+	CalendarFeed(w http.ResponseWriter, r *http.Request)
+}
+
+type calendarAPI struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarAPI(calendarService service.CalendarService) *calendarAPI {
+	return &calendarAPI{calendarService}
+}
+
+func (c *calendarAPI) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	feed, err := c.calendarService.GetFeed(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(calendarFeedResponse(r, feed))
+}
+
+func (c *calendarAPI) RegenerateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	feed, err := c.calendarService.RegenerateToken(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(calendarFeedResponse(r, feed))
+}
+
+func (c *calendarAPI) DeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	err = c.calendarService.DeleteFeed(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userIdInt,
+		"message": "success delete calendar feed",
+	})
+}
+
+// CalendarFeed is public, calendar apps authenticate with the token of the
+// URL only.
+func (c *calendarAPI) CalendarFeed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := entity.CalendarFilter{
+		Label: query.Get("label"),
+		Type:  query.Get("type"),
+	}
+
+	params := []struct {
+		param string
+		value *int
+	}{
+		{"board_id", &filter.BoardID},
+		{"category_id", &filter.CategoryID},
+	}
+	for _, param := range params {
+		if query.Get(param.param) == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(query.Get(param.param))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid "+param.param)
+			return
+		}
+		*param.value = id
+	}
+
+	ics, err := c.calendarService.RenderFeed(r.Context(), query.Get("token"), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(ics))
+}
+
+// calendarFeedResponse builds the subscription URL on the host the request
+// was made to.
+func calendarFeedResponse(r *http.Request, feed entity.CalendarFeed) entity.CalendarFeedResponse {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return entity.CalendarFeedResponse{
+		URL:       fmt.Sprintf("%s://%s/api/v1/calendar/feed.ics?token=%s", scheme, r.Host, url.QueryEscape(feed.Token)),
+		Token:     feed.Token,
+		UpdatedAt: feed.UpdatedAt,
+	}
+}
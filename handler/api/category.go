@@ -8,12 +8,15 @@ import (
 
 	"github.com/snykk/kanban-app/entity"
 	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
 )
 
 type CategoryAPI interface {
 	GetCategory(w http.ResponseWriter, r *http.Request)
+	GetCategoryByID(w http.ResponseWriter, r *http.Request)
 	CreateNewCategory(w http.ResponseWriter, r *http.Request)
 	DeleteCategory(w http.ResponseWriter, r *http.Request)
+	PatchCategory(w http.ResponseWriter, r *http.Request)
 	GetCategoryWithTasks(w http.ResponseWriter, r *http.Request)
 }
 
@@ -28,17 +31,14 @@ func NewCategoryAPI(categoryService service.CategoryService) *categoryAPI {
 func (c *categoryAPI) GetCategory(w http.ResponseWriter, r *http.Request) {
 	id := r.Context().Value("id").(string)
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
 	idInt, _ := strconv.Atoi(id)
 	categories, err := c.categoryService.GetCategories(r.Context(), idInt)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -46,27 +46,48 @@ func (c *categoryAPI) GetCategory(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(categories)
 }
 
+func (c *categoryAPI) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, _ := strconv.Atoi(userId)
+	if userId == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	categoryIdInt, _ := strconv.Atoi(r.URL.Query().Get("category_id"))
+	category, err := c.categoryService.GetCategoryByID(r.Context(), categoryIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if category.ID == 0 || category.UserID != userIdInt {
+		writeError(w, service.ErrCategoryNotFound)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
 func (c *categoryAPI) CreateNewCategory(w http.ResponseWriter, r *http.Request) {
 	var category entity.CategoryRequest
 
 	err := json.NewDecoder(r.Body).Decode(&category)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid category request"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid category request")
 		return
 	}
 
-	if category.Type == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid category request"))
+	if !validateRequest(w, category, "invalid category request") {
 		return
 	}
 
 	userId := r.Context().Value("id").(string)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
@@ -77,9 +98,7 @@ func (c *categoryAPI) CreateNewCategory(w http.ResponseWriter, r *http.Request)
 	}
 	createdCategory, err := c.categoryService.StoreCategory(r.Context(), &entityCategory)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -95,8 +114,12 @@ func (c *categoryAPI) CreateNewCategory(w http.ResponseWriter, r *http.Request)
 func (c *categoryAPI) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value("id").(string)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
@@ -104,11 +127,9 @@ func (c *categoryAPI) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	categoryIdInt, _ := strconv.Atoi(categoryID)
 	userIdInt, _ := strconv.Atoi(userId)
 
-	err := c.categoryService.DeleteCategory(r.Context(), categoryIdInt)
+	err := c.categoryService.DeleteCategory(r.Context(), userIdInt, categoryIdInt, version)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -121,21 +142,59 @@ func (c *categoryAPI) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (c *categoryAPI) PatchCategory(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, _ := strconv.Atoi(userId)
+	if userId == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	categoryIdInt, _ := strconv.Atoi(r.URL.Query().Get("category_id"))
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := decodeMergePatch(r)
+	if err != nil {
+		writePatchDecodeError(w, err)
+		return
+	}
+
+	patch, fields := decodeCategoryPatch(members)
+	if len(fields) > 0 {
+		writeError(w, service.NewValidationError("invalid category patch", fields))
+		return
+	}
+
+	patch.Version = version
+
+	category, err := c.categoryService.PatchCategory(r.Context(), userIdInt, categoryIdInt, patch)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(category)
+}
+
 func (c *categoryAPI) GetCategoryWithTasks(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value("id")
 
 	idLogin, err := strconv.Atoi(userId.(string))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println("get category task", err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
 	categories, err := c.categoryService.GetCategoriesWithTasks(r.Context(), int(idLogin))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("internal server error"))
+		utils.WriteError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// collabHeartbeatTimeout drops connections that sent nothing, not even
+	// a ping, for this long.
+	collabHeartbeatTimeout = 60 * time.Second
+	collabWriteTimeout     = 10 * time.Second
+)
+
+var errUnknownCollabMessage = service.NewValidationError("unknown message type", []entity.FieldError{{Field: "type", Message: "must be edit, stop_edit or ping"}})
+
+type CollabAPI interface {
+	Connect(w http.ResponseWriter, r *http.Request)
+}
+
+type collabAPI struct {
+	collabHub   service.CollabHub
+	userService service.UserService
+}
+
+func NewCollabAPI(collabHub service.CollabHub, userService service.UserService) *collabAPI {
+	return &collabAPI{collabHub, userService}
+}
+
+// Connect upgrades to a WebSocket on the user's board. The client gets the
+// presence of the board and its events, and sends edit/stop_edit to hold
+// a soft lock on a task and ping to stay connected.
+func (c *collabAPI) Connect(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	user, err := c.userService.GetUserById(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: sameOrigin,
+		Handler: func(ws *websocket.Conn) {
+			c.serve(ws, userIdInt, user)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+func (c *collabAPI) serve(ws *websocket.Conn, boardId int, user entity.User) {
+	defer ws.Close()
+
+	client := c.collabHub.Join(boardId, user)
+	defer c.collabHub.Leave(client)
+
+	go func() {
+		for message := range client.Messages() {
+			ws.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+			err := websocket.JSON.Send(ws, message)
+			if err != nil {
+				break
+			}
+		}
+		// unblocks the read below when the client was dropped
+		ws.Close()
+	}()
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(collabHeartbeatTimeout))
+
+		var message entity.CollabMessage
+		err := websocket.JSON.Receive(ws, &message)
+		if err != nil {
+			return
+		}
+
+		switch message.Type {
+		case entity.CollabPing:
+			c.collabHub.Send(client, entity.CollabMessage{Type: entity.CollabPong})
+		case entity.CollabEdit:
+			err = c.collabHub.Edit(ws.Request().Context(), client, message.TaskID)
+		case entity.CollabStopEdit:
+			c.collabHub.StopEdit(client)
+		default:
+			err = errUnknownCollabMessage
+		}
+
+		if err != nil {
+			problem := problemOf(err)
+			c.collabHub.Send(client, entity.CollabMessage{Type: entity.CollabError, TaskID: message.TaskID, Error: &problem})
+		}
+	}
+}
+
+// sameOrigin refuses cross-site pages, which would otherwise connect with
+// the user's cookie. Clients that send no Origin are not browsers.
+func sameOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	originURL, err := url.ParseRequestURI(origin)
+	if err != nil {
+		return err
+	}
+
+	if originURL.Host != r.Host {
+		return errors.New("cross origin websocket request")
+	}
+	config.Origin = originURL
+	return nil
+}
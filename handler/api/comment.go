@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type CommentAPI interface {
+	GetComments(w http.ResponseWriter, r *http.Request)
+	CreateNewComment(w http.ResponseWriter, r *http.Request)
+}
+
+type commentAPI struct {
+	commentService service.CommentService
+}
+
+func NewCommentAPI(commentService service.CommentService) *commentAPI {
+	return &commentAPI{commentService}
+}
+
+func (c *commentAPI) GetComments(w http.ResponseWriter, r *http.Request) {
+	taskIdInt, err := strconv.Atoi(r.URL.Query().Get("task_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid task id")
+		return
+	}
+
+	userIdInt, err := strconv.Atoi(r.Context().Value("id").(string))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	comments, err := c.commentService.GetComments(r.Context(), userIdInt, taskIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(comments)
+}
+
+func (c *commentAPI) CreateNewComment(w http.ResponseWriter, r *http.Request) {
+	var comment entity.CommentRequest
+
+	err := json.NewDecoder(r.Body).Decode(&comment)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid comment request")
+		return
+	}
+
+	if !validateRequest(w, comment, "invalid comment request") {
+		return
+	}
+
+	taskIdInt, err := strconv.Atoi(r.URL.Query().Get("task_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid task id")
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	entityComment := entity.Comment{
+		TaskID: taskIdInt,
+		UserID: userIdInt,
+		Body:   comment.Body,
+	}
+	createdComment, err := c.commentService.StoreComment(r.Context(), &entityComment)
+	if err != nil {
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":    userIdInt,
+		"task_id":    taskIdInt,
+		"comment_id": createdComment.ID,
+		"message":    "success create new comment",
+	})
+}
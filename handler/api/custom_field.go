@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type CustomFieldAPI interface {
+	GetCustomFields(w http.ResponseWriter, r *http.Request)
+	CreateNewCustomField(w http.ResponseWriter, r *http.Request)
+	DeleteCustomField(w http.ResponseWriter, r *http.Request)
+}
+
+type customFieldAPI struct {
+	customFieldService service.CustomFieldService
+}
+
+func NewCustomFieldAPI(customFieldService service.CustomFieldService) *customFieldAPI {
+	return &customFieldAPI{customFieldService}
+}
+
+func (c *customFieldAPI) GetCustomFields(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	fields, err := c.customFieldService.GetCustomFields(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(fields)
+}
+
+func (c *customFieldAPI) CreateNewCustomField(w http.ResponseWriter, r *http.Request) {
+	var field entity.CustomFieldRequest
+
+	err := json.NewDecoder(r.Body).Decode(&field)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid custom field request")
+		return
+	}
+
+	if !validateRequest(w, field, "invalid custom field request") {
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	entityField := entity.CustomField{
+		Name:    field.Name,
+		Type:    field.Type,
+		Options: field.Options,
+		UserID:  userIdInt,
+	}
+	createdField, err := c.customFieldService.StoreCustomField(r.Context(), &entityField)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":  userIdInt,
+		"field_id": createdField.ID,
+		"message":  "success create new custom field",
+	})
+}
+
+func (c *customFieldAPI) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	fieldIdInt, _ := strconv.Atoi(r.URL.Query().Get("field_id"))
+
+	err = c.customFieldService.DeleteCustomField(r.Context(), userIdInt, fieldIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":  userIdInt,
+		"field_id": fieldIdInt,
+		"message":  "success delete custom field",
+	})
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/snykk/kanban-app/utils"
+)
+
+// ifMatchVersion reads the version a write was made against from the
+// If-Match header, writing a precondition failure for a weak tag and a bad
+// request when it cannot be parsed.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	version, err := utils.ParseETag(r.Header.Get("If-Match"))
+	if errors.Is(err, utils.ErrWeakETag) {
+		utils.WriteError(w, http.StatusPreconditionFailed, "weak entity tags never match If-Match")
+		return 0, false
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid If-Match header")
+		return 0, false
+	}
+	return version, true
+}
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", utils.ETag(version))
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// eventHeartbeat keeps idle streams from being cut by proxies.
+const eventHeartbeat = 15 * time.Second
+
+type EventAPI interface {
+	StreamBoardEvents(w http.ResponseWriter, r *http.Request)
+}
+
+type eventAPI struct {
+	eventBus service.EventBus
+}
+
+func NewEventAPI(eventBus service.EventBus) *eventAPI {
+	return &eventAPI{eventBus}
+}
+
+// StreamBoardEvents streams the events of the user's board as
+// Server-Sent Events until the client goes away. The stream ends when the
+// client falls behind, EventSource then reconnects on its own.
+func (e *eventAPI) StreamBoardEvents(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	events, unsubscribe := e.eventBus.Subscribe(userIdInt)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Println("encode board event:", err.Error())
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,647 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type GraphQLAPI interface {
+	Query(w http.ResponseWriter, r *http.Request)
+}
+
+type graphQLAPI struct {
+	taskService     service.TaskService
+	categoryService service.CategoryService
+	userService     service.UserService
+	loaderService   service.LoaderService
+	schema          graphql.Schema
+}
+
+// graphQLBoard is the source of the Board type. Its categories are read
+// up front since every board field needs them.
+type graphQLBoard struct {
+	ID         int
+	Categories []entity.Category
+}
+
+type loadersKey struct{}
+
+func NewGraphQLAPI(taskService service.TaskService, categoryService service.CategoryService, userService service.UserService, loaderService service.LoaderService) *graphQLAPI {
+	g := &graphQLAPI{
+		taskService:     taskService,
+		categoryService: categoryService,
+		userService:     userService,
+		loaderService:   loaderService,
+	}
+
+	schema, err := graphql.NewSchema(g.schemaConfig())
+	if err != nil {
+		// the schema is static, an error here is a bug
+		panic(err)
+	}
+	g.schema = schema
+
+	return g
+}
+
+func (g *graphQLAPI) Query(w http.ResponseWriter, r *http.Request) {
+	var req entity.GraphQLRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid graphql request")
+		return
+	}
+
+	if !validateRequest(w, req, "invalid graphql request") {
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	if userId == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), loadersKey{}, g.loaderService.NewLoaders(r.Context()))
+	result := graphql.Do(graphql.Params{
+		Schema:         g.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+
+	response := entity.GraphQLResponse{Data: result.Data}
+	for _, err := range result.Errors {
+		response.Errors = append(response.Errors, entity.GraphQLError{
+			Message:    err.Message,
+			Path:       err.Path,
+			Extensions: err.Extensions,
+		})
+	}
+
+	// root fields are nullable, so data is only missing when the document
+	// did not parse or validate
+	status := http.StatusOK
+	if result.Data == nil && len(result.Errors) > 0 {
+		status = http.StatusBadRequest
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (g *graphQLAPI) schemaConfig() graphql.SchemaConfig {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"fullname":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"created_at": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	// categories and tasks refer to each other, so their fields are thunks
+	var taskType *graphql.Object
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"type":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"user_id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"version":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"created_at": &graphql.Field{Type: graphql.DateTime},
+				"updated_at": &graphql.Field{Type: graphql.DateTime},
+				"user": &graphql.Field{
+					Type: userType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return load(loadersOf(p).Users, p.Source.(entity.Category).UserID), nil
+					},
+				},
+				"tasks": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(taskType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return load(loadersOf(p).TasksByCategory, p.Source.(entity.Category).ID), nil
+					},
+				},
+				"task_count": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return countTasks(loadersOf(p), p.Source.(entity.Category)), nil
+					},
+				},
+				"total_estimate": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Float),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return sumEstimates(loadersOf(p), p.Source.(entity.Category)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	taskType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"category_id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"user_id":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"estimate":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"labels":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"assignee_id": &graphql.Field{Type: graphql.Int},
+			"archived_at": &graphql.Field{Type: graphql.DateTime},
+			"due_date": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					dueDate := p.Source.(entity.Task).DueDate
+					if dueDate == nil {
+						return nil, nil
+					}
+					return dueDate.Format(entity.DateLayout), nil
+				},
+			},
+			"version":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"created_at": &graphql.Field{Type: graphql.DateTime},
+			"updated_at": &graphql.Field{Type: graphql.DateTime},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return load(loadersOf(p).Categories, p.Source.(entity.Task).CategoryID), nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return load(loadersOf(p).Users, p.Source.(entity.Task).UserID), nil
+				},
+			},
+			"assignee": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					assigneeId := p.Source.(entity.Task).AssigneeID
+					if assigneeId == nil {
+						return nil, nil
+					}
+					return load(loadersOf(p).Users, *assigneeId), nil
+				},
+			},
+		},
+	})
+
+	boardType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Board",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"owner": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return load(loadersOf(p).Users, p.Source.(graphQLBoard).ID), nil
+				},
+			},
+			"categories": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(graphQLBoard).Categories, nil
+				},
+			},
+			"task_count": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return countTasks(loadersOf(p), p.Source.(graphQLBoard).Categories...), nil
+				},
+			},
+			"total_estimate": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Float),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return sumEstimates(loadersOf(p), p.Source.(graphQLBoard).Categories...), nil
+				},
+			},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:    userType,
+				Resolve: g.resolveMe,
+			},
+			"board": &graphql.Field{
+				Type:    boardType,
+				Args:    idArgs,
+				Resolve: g.resolveBoard,
+			},
+			"category": &graphql.Field{
+				Type:    categoryType,
+				Args:    idArgs,
+				Resolve: g.resolveCategory,
+			},
+			"task": &graphql.Field{
+				Type:    taskType,
+				Args:    idArgs,
+				Resolve: g.resolveTask,
+			},
+			"user": &graphql.Field{
+				Type:    userType,
+				Args:    idArgs,
+				Resolve: g.resolveUser,
+			},
+		},
+	})
+
+	taskInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TaskInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"category_id": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"estimate":    &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"due_date":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	taskPatchInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TaskPatchInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"estimate":    &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"due_date":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	// version is optional everywhere, 0 or absent skips the check
+	versionArg := &graphql.ArgumentConfig{Type: graphql.Int}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(taskInput)},
+				},
+				Resolve: g.createTask,
+			},
+			"updateTask": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"patch":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(taskPatchInput)},
+					"version": versionArg,
+				},
+				Resolve: g.updateTask,
+			},
+			"moveTask": &graphql.Field{
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"id":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"category_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"version":     versionArg,
+				},
+				Resolve: g.moveTask,
+			},
+			"deleteTask": &graphql.Field{
+				Type: graphql.Int,
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"version": versionArg,
+				},
+				Resolve: g.deleteTask,
+			},
+			"createCategory": &graphql.Field{
+				Type: categoryType,
+				Args: graphql.FieldConfigArgument{
+					"type": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: g.createCategory,
+			},
+			"updateCategory": &graphql.Field{
+				Type: categoryType,
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"type":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"version": versionArg,
+				},
+				Resolve: g.updateCategory,
+			},
+			"deleteCategory": &graphql.Field{
+				Type: graphql.Int,
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"version": versionArg,
+				},
+				Resolve: g.deleteCategory,
+			},
+		},
+	})
+
+	return graphql.SchemaConfig{Query: query, Mutation: mutation}
+}
+
+func (g *graphQLAPI) resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	return load(loadersOf(p).Users, viewerOf(p)), nil
+}
+
+func (g *graphQLAPI) resolveBoard(p graphql.ResolveParams) (interface{}, error) {
+	// a board is identified by its owner's user id
+	boardId := p.Args["id"].(int)
+	if boardId != viewerOf(p) {
+		return nil, graphQLError(service.ErrBoardNotFound)
+	}
+
+	categories, err := g.categoryService.GetCategories(p.Context, boardId)
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+
+	return graphQLBoard{ID: boardId, Categories: categories}, nil
+}
+
+func (g *graphQLAPI) resolveCategory(p graphql.ResolveParams) (interface{}, error) {
+	category, err := g.ownedCategory(p.Context, viewerOf(p), p.Args["id"].(int))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return category, nil
+}
+
+func (g *graphQLAPI) resolveTask(p graphql.ResolveParams) (interface{}, error) {
+	task, err := g.ownedTask(p.Context, viewerOf(p), p.Args["id"].(int))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return task, nil
+}
+
+func (g *graphQLAPI) resolveUser(p graphql.ResolveParams) (interface{}, error) {
+	user, err := g.userService.GetUserById(p.Context, p.Args["id"].(int))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	if user.ID == 0 {
+		return nil, graphQLError(service.ErrUserNotFound)
+	}
+	return user, nil
+}
+
+func (g *graphQLAPI) createTask(p graphql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+	req := entity.TaskRequest{
+		Title:       input["title"].(string),
+		Description: input["description"].(string),
+		CategoryID:  input["category_id"].(int),
+	}
+	if estimate, ok := input["estimate"].(float64); ok {
+		req.Estimate = estimate
+	}
+	if dueDate, ok := input["due_date"].(string); ok {
+		req.DueDate = dueDate
+	}
+
+	dueDate, dueDateErr := parseDueDate(req.DueDate)
+	if fields := append(utils.Validate(req), dueDateErr...); len(fields) > 0 {
+		return nil, graphQLError(service.NewValidationError("invalid task request", fields))
+	}
+
+	userId := viewerOf(p)
+	_, err := g.ownedCategory(p.Context, userId, req.CategoryID)
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+
+	task, err := g.taskService.StoreTask(p.Context, &entity.Task{
+		Title:       req.Title,
+		Description: req.Description,
+		CategoryID:  req.CategoryID,
+		UserID:      userId,
+		Estimate:    req.Estimate,
+		DueDate:     dueDate,
+	})
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+
+	// read back for the defaults and timestamps set by the database
+	task, err = g.taskService.GetTaskByID(p.Context, task.ID)
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return task, nil
+}
+
+func (g *graphQLAPI) updateTask(p graphql.ResolveParams) (interface{}, error) {
+	// the input goes through the merge patch rules of PATCH /tasks
+	members := map[string]json.RawMessage{}
+	for name, value := range p.Args["patch"].(map[string]interface{}) {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, graphQLError(err)
+		}
+		members[name] = raw
+	}
+
+	patch, fields := decodeTaskPatch(members)
+	if len(fields) > 0 {
+		return nil, graphQLError(service.NewValidationError("invalid task patch", fields))
+	}
+
+	patch.Version = intArg(p, "version")
+	return g.patchTask(p, patch)
+}
+
+func (g *graphQLAPI) moveTask(p graphql.ResolveParams) (interface{}, error) {
+	categoryId := p.Args["category_id"].(int)
+	return g.patchTask(p, entity.TaskPatch{CategoryID: &categoryId, Version: intArg(p, "version")})
+}
+
+func (g *graphQLAPI) patchTask(p graphql.ResolveParams, patch entity.TaskPatch) (interface{}, error) {
+	task, err := g.taskService.PatchTask(p.Context, viewerOf(p), p.Args["id"].(int), patch)
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return task, nil
+}
+
+func (g *graphQLAPI) deleteTask(p graphql.ResolveParams) (interface{}, error) {
+	task, err := g.ownedTask(p.Context, viewerOf(p), p.Args["id"].(int))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+
+	err = g.taskService.DeleteTask(p.Context, viewerOf(p), task.ID, intArg(p, "version"))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return task.ID, nil
+}
+
+func (g *graphQLAPI) createCategory(p graphql.ResolveParams) (interface{}, error) {
+	req := entity.CategoryRequest{Type: p.Args["type"].(string)}
+	if fields := utils.Validate(req); len(fields) > 0 {
+		return nil, graphQLError(service.NewValidationError("invalid category request", fields))
+	}
+
+	category, err := g.categoryService.StoreCategory(p.Context, &entity.Category{Type: req.Type, UserID: viewerOf(p)})
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+
+	category, err = g.categoryService.GetCategoryByID(p.Context, category.ID)
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return category, nil
+}
+
+func (g *graphQLAPI) updateCategory(p graphql.ResolveParams) (interface{}, error) {
+	categoryType := p.Args["type"].(string)
+	if strings.TrimSpace(categoryType) == "" {
+		return nil, graphQLError(service.NewValidationError("invalid category patch", []entity.FieldError{{Field: "type", Message: "is required"}}))
+	}
+
+	category, err := g.categoryService.PatchCategory(p.Context, viewerOf(p), p.Args["id"].(int), entity.CategoryPatch{Type: &categoryType, Version: intArg(p, "version")})
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return category, nil
+}
+
+func (g *graphQLAPI) deleteCategory(p graphql.ResolveParams) (interface{}, error) {
+	category, err := g.ownedCategory(p.Context, viewerOf(p), p.Args["id"].(int))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+
+	err = g.categoryService.DeleteCategory(p.Context, viewerOf(p), category.ID, intArg(p, "version"))
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	return category.ID, nil
+}
+
+func (g *graphQLAPI) ownedTask(ctx context.Context, userId int, id int) (entity.Task, error) {
+	task, err := g.taskService.GetTaskByID(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if task.ID == 0 || task.UserID != userId {
+		return entity.Task{}, service.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (g *graphQLAPI) ownedCategory(ctx context.Context, userId int, id int) (entity.Category, error) {
+	category, err := g.categoryService.GetCategoryByID(ctx, id)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	if category.ID == 0 || category.UserID != userId {
+		return entity.Category{}, service.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func viewerOf(p graphql.ResolveParams) int {
+	userId, _ := strconv.Atoi(p.Context.Value("id").(string))
+	return userId
+}
+
+func loadersOf(p graphql.ResolveParams) *service.Loaders {
+	return p.Context.Value(loadersKey{}).(*service.Loaders)
+}
+
+func intArg(p graphql.ResolveParams, name string) int {
+	value, _ := p.Args[name].(int)
+	return value
+}
+
+// load defers a loader lookup so sibling fields are fetched in one batch.
+func load(loader *service.Loader, key int) func() (interface{}, error) {
+	thunk := loader.Load(key)
+	return func() (interface{}, error) {
+		value, err := thunk()
+		if err != nil {
+			return nil, graphQLError(err)
+		}
+		return value, nil
+	}
+}
+
+func countTasks(loaders *service.Loaders, categories ...entity.Category) func() (interface{}, error) {
+	return sumTasks(loaders, categories, func(task entity.Task) float64 { return 1 }, func(sum float64) interface{} { return int(sum) })
+}
+
+func sumEstimates(loaders *service.Loaders, categories ...entity.Category) func() (interface{}, error) {
+	return sumTasks(loaders, categories, func(task entity.Task) float64 { return task.Estimate }, func(sum float64) interface{} { return sum })
+}
+
+// sumTasks queues the tasks of every category before any is fetched, so a
+// total over the board costs one query.
+func sumTasks(loaders *service.Loaders, categories []entity.Category, value func(entity.Task) float64, result func(float64) interface{}) func() (interface{}, error) {
+	thunks := make([]func() (interface{}, error), 0, len(categories))
+	for _, category := range categories {
+		thunks = append(thunks, load(loaders.TasksByCategory, category.ID))
+	}
+
+	return func() (interface{}, error) {
+		var sum float64
+		for _, thunk := range thunks {
+			tasks, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, task := range tasks.([]entity.Task) {
+				sum += value(task)
+			}
+		}
+		return result(sum), nil
+	}
+}
+
+// graphQLExtendedError reports an error the way writeError would, with the
+// problem code and status in the extensions of the GraphQL error.
+type graphQLExtendedError struct {
+	problem entity.Problem
+}
+
+func (e graphQLExtendedError) Error() string {
+	return e.problem.Detail
+}
+
+func (e graphQLExtendedError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":   e.problem.Code,
+		"status": e.problem.Status,
+	}
+	if len(e.problem.Errors) > 0 {
+		extensions["errors"] = e.problem.Errors
+	}
+	return extensions
+}
+
+func graphQLError(err error) error {
+	return graphQLExtendedError{problemOf(err)}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	kanbanv1 "github.com/snykk/kanban-app/proto/kanban/v1"
+	"github.com/snykk/kanban-app/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var errorKindCode = map[service.ErrorKind]codes.Code{
+	service.KindInvalid:            codes.InvalidArgument,
+	service.KindUnauthorized:       codes.Unauthenticated,
+	service.KindNotFound:           codes.NotFound,
+	service.KindConflict:           codes.AlreadyExists,
+	service.KindPreconditionFailed: codes.FailedPrecondition,
+	service.KindValidation:         codes.InvalidArgument,
+}
+
+// grpcError maps a service error to a status whose message starts with the
+// problem code, followed by the field errors if any. Errors without a
+// domain meaning are logged and hidden, as problemOf does.
+func grpcError(err error) error {
+	domainErr, ok := service.AsError(err)
+	if !ok || errorKindCode[domainErr.Kind] == 0 {
+		log.Println(err.Error())
+		return status.Error(codes.Internal, "error internal server")
+	}
+
+	message := domainErr.Code + ": " + domainErr.Message
+	if len(domainErr.Fields) > 0 {
+		fields := make([]string, 0, len(domainErr.Fields))
+		for _, field := range domainErr.Fields {
+			fields = append(fields, field.Field+" "+field.Message)
+		}
+		message += ": " + strings.Join(fields, "; ")
+	}
+	return status.Error(errorKindCode[domainErr.Kind], message)
+}
+
+// grpcViewer reads the user id put in the context by middleware.GRPCAuth.
+func grpcViewer(ctx context.Context) int {
+	userId, _ := strconv.Atoi(ctx.Value("id").(string))
+	return userId
+}
+
+// grpcPatchMembers encodes the set fields of a request as merge patch
+// members, so they go through the rules of PATCH on the HTTP API.
+func grpcPatchMembers(values map[string]interface{}) (map[string]json.RawMessage, error) {
+	members := map[string]json.RawMessage{}
+	for name, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		members[name] = raw
+	}
+	return members, nil
+}
+
+func grpcUser(user entity.User) *kanbanv1.User {
+	return &kanbanv1.User{
+		Id:        int64(user.ID),
+		Fullname:  user.Fullname,
+		Email:     user.Email,
+		CreatedAt: grpcTimestamp(user.CreatedAt),
+	}
+}
+
+func grpcCategory(category entity.Category) *kanbanv1.Category {
+	return &kanbanv1.Category{
+		Id:        int64(category.ID),
+		Type:      category.Type,
+		UserId:    int64(category.UserID),
+		Version:   int64(category.Version),
+		CreatedAt: grpcTimestamp(category.CreatedAt),
+		UpdatedAt: grpcTimestamp(category.UpdatedAt),
+	}
+}
+
+func grpcTask(task entity.Task) *kanbanv1.Task {
+	message := &kanbanv1.Task{
+		Id:          int64(task.ID),
+		Title:       task.Title,
+		Description: task.Description,
+		CategoryId:  int64(task.CategoryID),
+		UserId:      int64(task.UserID),
+		Estimate:    task.Estimate,
+		Labels:      task.Labels,
+		Version:     int64(task.Version),
+		CreatedAt:   grpcTimestamp(task.CreatedAt),
+		UpdatedAt:   grpcTimestamp(task.UpdatedAt),
+	}
+	if task.AssigneeID != nil {
+		assigneeId := int64(*task.AssigneeID)
+		message.AssigneeId = &assigneeId
+	}
+	if task.ArchivedAt != nil {
+		message.ArchivedAt = grpcTimestamp(*task.ArchivedAt)
+	}
+	return message
+}
+
+func grpcTasks(tasks []entity.Task) []*kanbanv1.Task {
+	messages := make([]*kanbanv1.Task, 0, len(tasks))
+	for _, task := range tasks {
+		messages = append(messages, grpcTask(task))
+	}
+	return messages
+}
+
+func grpcBoardEvent(event entity.BoardEvent) *kanbanv1.BoardEvent {
+	message := &kanbanv1.BoardEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		BoardId:    int64(event.BoardID),
+		ActorId:    int64(event.ActorID),
+		TaskId:     int64(event.TaskID),
+		CategoryId: int64(event.CategoryID),
+		At:         grpcTimestamp(event.At),
+	}
+	if event.Task != nil {
+		message.Task = grpcTask(*event.Task)
+	}
+	if event.Category != nil {
+		message.Category = grpcCategory(*event.Category)
+	}
+	return message
+}
+
+// grpcTimestamp leaves zero times unset.
+func grpcTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
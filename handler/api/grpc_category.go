@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+
+	"github.com/snykk/kanban-app/entity"
+	kanbanv1 "github.com/snykk/kanban-app/proto/kanban/v1"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type categoryGRPC struct {
+	kanbanv1.UnimplementedCategoryServiceServer
+	categoryService service.CategoryService
+	eventBus        service.EventBus
+}
+
+func NewCategoryGRPC(categoryService service.CategoryService, eventBus service.EventBus) *categoryGRPC {
+	return &categoryGRPC{categoryService: categoryService, eventBus: eventBus}
+}
+
+func (c *categoryGRPC) ListCategories(ctx context.Context, req *kanbanv1.ListCategoriesRequest) (*kanbanv1.ListCategoriesResponse, error) {
+	categories, err := c.categoryService.GetCategories(ctx, grpcViewer(ctx))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	res := &kanbanv1.ListCategoriesResponse{Categories: make([]*kanbanv1.Category, 0, len(categories))}
+	for _, category := range categories {
+		res.Categories = append(res.Categories, grpcCategory(category))
+	}
+	return res, nil
+}
+
+func (c *categoryGRPC) GetCategory(ctx context.Context, req *kanbanv1.GetCategoryRequest) (*kanbanv1.Category, error) {
+	category, err := c.ownedCategory(ctx, int(req.Id))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcCategory(category), nil
+}
+
+func (c *categoryGRPC) CreateCategory(ctx context.Context, req *kanbanv1.CreateCategoryRequest) (*kanbanv1.Category, error) {
+	request := entity.CategoryRequest{Type: req.Type}
+	if fields := utils.Validate(request); len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid category request", fields))
+	}
+
+	category, err := c.categoryService.StoreCategory(ctx, &entity.Category{Type: request.Type, UserID: grpcViewer(ctx)})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	// read back for the defaults and timestamps set by the database
+	category, err = c.categoryService.GetCategoryByID(ctx, category.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcCategory(category), nil
+}
+
+func (c *categoryGRPC) UpdateCategory(ctx context.Context, req *kanbanv1.UpdateCategoryRequest) (*kanbanv1.Category, error) {
+	members, err := grpcPatchMembers(map[string]interface{}{"type": req.Type})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	patch, fields := decodeCategoryPatch(members)
+	if len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid category patch", fields))
+	}
+
+	patch.Version = int(req.Version)
+	category, err := c.categoryService.PatchCategory(ctx, grpcViewer(ctx), int(req.Id), patch)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcCategory(category), nil
+}
+
+func (c *categoryGRPC) DeleteCategory(ctx context.Context, req *kanbanv1.DeleteCategoryRequest) (*kanbanv1.DeleteResponse, error) {
+	err := c.categoryService.DeleteCategory(ctx, grpcViewer(ctx), int(req.Id), int(req.Version))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &kanbanv1.DeleteResponse{Id: req.Id}, nil
+}
+
+func (c *categoryGRPC) GetBoard(ctx context.Context, req *kanbanv1.GetBoardRequest) (*kanbanv1.Board, error) {
+	// a board is identified by its owner's user id
+	boardId := grpcViewer(ctx)
+
+	categories, err := c.categoryService.GetCategories(ctx, boardId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	columns, err := c.categoryService.GetCategoriesWithTasks(ctx, boardId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	columnOf := map[int]entity.CategoryData{}
+	for _, column := range columns {
+		columnOf[column.ID] = column
+	}
+
+	board := &kanbanv1.Board{Id: int64(boardId), Columns: make([]*kanbanv1.BoardColumn, 0, len(categories))}
+	for _, category := range categories {
+		column := columnOf[category.ID]
+		board.Columns = append(board.Columns, &kanbanv1.BoardColumn{
+			Category:      grpcCategory(category),
+			Tasks:         grpcTasks(column.Tasks),
+			TotalEstimate: column.TotalEstimate,
+		})
+		board.TotalEstimate += column.TotalEstimate
+	}
+	return board, nil
+}
+
+// StreamBoardEvents sends the events of the user's board until the call is
+// cancelled. A client that falls behind gets UNAVAILABLE and should reload
+// the board before subscribing again.
+func (c *categoryGRPC) StreamBoardEvents(req *kanbanv1.StreamBoardEventsRequest, stream kanbanv1.CategoryService_StreamBoardEventsServer) error {
+	events, unsubscribe := c.eventBus.Subscribe(grpcViewer(stream.Context()))
+	defer unsubscribe()
+
+	// the headers tell the client it is subscribed
+	err := stream.SendHeader(metadata.MD{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream fell behind, reload the board and subscribe again")
+			}
+
+			err := stream.Send(grpcBoardEvent(event))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *categoryGRPC) ownedCategory(ctx context.Context, id int) (entity.Category, error) {
+	category, err := c.categoryService.GetCategoryByID(ctx, id)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	if category.ID == 0 || category.UserID != grpcViewer(ctx) {
+		return entity.Category{}, service.ErrCategoryNotFound
+	}
+	return category, nil
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/snykk/kanban-app/entity"
+	kanbanv1 "github.com/snykk/kanban-app/proto/kanban/v1"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type taskGRPC struct {
+	kanbanv1.UnimplementedTaskServiceServer
+	taskService     service.TaskService
+	categoryService service.CategoryService
+}
+
+func NewTaskGRPC(taskService service.TaskService, categoryService service.CategoryService) *taskGRPC {
+	return &taskGRPC{taskService: taskService, categoryService: categoryService}
+}
+
+func (t *taskGRPC) ListTasks(ctx context.Context, req *kanbanv1.ListTasksRequest) (*kanbanv1.ListTasksResponse, error) {
+	filter := entity.TaskFilter{
+		Archived:   req.Archived,
+		CategoryID: int(req.CategoryId),
+		Query:      strings.TrimSpace(req.Query),
+		Page:       1,
+		PerPage:    entity.DefaultTaskPerPage,
+	}
+
+	// zero values take the defaults of the HTTP listing
+	var fields []entity.FieldError
+	if req.Page < 0 {
+		fields = append(fields, entity.FieldError{Field: "page", Message: "must be a positive integer"})
+	} else if req.Page > 0 {
+		filter.Page = int(req.Page)
+	}
+	if req.PerPage < 0 || req.PerPage > entity.MaxTaskPerPage {
+		fields = append(fields, entity.FieldError{Field: "per_page", Message: fmt.Sprintf("must be from 1 to %d", entity.MaxTaskPerPage)})
+	} else if req.PerPage > 0 {
+		filter.PerPage = int(req.PerPage)
+	}
+	if len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid task listing", fields))
+	}
+
+	page, err := t.taskService.GetTasksPage(ctx, grpcViewer(ctx), filter)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &kanbanv1.ListTasksResponse{
+		Tasks:      grpcTasks(page.Data),
+		Total:      page.Total,
+		Page:       int32(page.Page),
+		PerPage:    int32(page.PerPage),
+		TotalPages: int32(page.TotalPages),
+	}, nil
+}
+
+func (t *taskGRPC) GetTask(ctx context.Context, req *kanbanv1.GetTaskRequest) (*kanbanv1.Task, error) {
+	task, err := t.taskService.GetTaskByID(ctx, int(req.Id))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	if task.ID == 0 || task.UserID != grpcViewer(ctx) {
+		return nil, grpcError(service.ErrTaskNotFound)
+	}
+	return grpcTask(task), nil
+}
+
+func (t *taskGRPC) CreateTask(ctx context.Context, req *kanbanv1.CreateTaskRequest) (*kanbanv1.Task, error) {
+	request := entity.TaskRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		CategoryID:  int(req.CategoryId),
+		Estimate:    req.Estimate,
+	}
+	if fields := utils.Validate(request); len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid task request", fields))
+	}
+
+	userId := grpcViewer(ctx)
+	category, err := t.categoryService.GetCategoryByID(ctx, request.CategoryID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if category.ID == 0 || category.UserID != userId {
+		return nil, grpcError(service.ErrCategoryNotFound)
+	}
+
+	task, err := t.taskService.StoreTask(ctx, &entity.Task{
+		Title:       request.Title,
+		Description: request.Description,
+		CategoryID:  request.CategoryID,
+		UserID:      userId,
+		Estimate:    request.Estimate,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	// read back for the defaults and timestamps set by the database
+	task, err = t.taskService.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcTask(task), nil
+}
+
+func (t *taskGRPC) UpdateTask(ctx context.Context, req *kanbanv1.UpdateTaskRequest) (*kanbanv1.Task, error) {
+	values := map[string]interface{}{}
+	if req.Title != nil {
+		values["title"] = *req.Title
+	}
+	if req.Description != nil {
+		values["description"] = *req.Description
+	}
+	if req.Estimate != nil {
+		values["estimate"] = *req.Estimate
+	}
+
+	members, err := grpcPatchMembers(values)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	patch, fields := decodeTaskPatch(members)
+	if len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid task patch", fields))
+	}
+
+	patch.Version = int(req.Version)
+	return t.patchTask(ctx, int(req.Id), patch)
+}
+
+func (t *taskGRPC) MoveTask(ctx context.Context, req *kanbanv1.MoveTaskRequest) (*kanbanv1.Task, error) {
+	categoryId := int(req.CategoryId)
+	return t.patchTask(ctx, int(req.Id), entity.TaskPatch{CategoryID: &categoryId, Version: int(req.Version)})
+}
+
+func (t *taskGRPC) DeleteTask(ctx context.Context, req *kanbanv1.DeleteTaskRequest) (*kanbanv1.DeleteResponse, error) {
+	err := t.taskService.DeleteTask(ctx, grpcViewer(ctx), int(req.Id), int(req.Version))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &kanbanv1.DeleteResponse{Id: req.Id}, nil
+}
+
+func (t *taskGRPC) patchTask(ctx context.Context, id int, patch entity.TaskPatch) (*kanbanv1.Task, error) {
+	task, err := t.taskService.PatchTask(ctx, grpcViewer(ctx), id, patch)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcTask(task), nil
+}
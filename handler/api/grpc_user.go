@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+
+	"github.com/snykk/kanban-app/entity"
+	kanbanv1 "github.com/snykk/kanban-app/proto/kanban/v1"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type userGRPC struct {
+	kanbanv1.UnimplementedUserServiceServer
+	userService service.UserService
+}
+
+func NewUserGRPC(userService service.UserService) *userGRPC {
+	return &userGRPC{userService: userService}
+}
+
+func (u *userGRPC) Login(ctx context.Context, req *kanbanv1.LoginRequest) (*kanbanv1.LoginResponse, error) {
+	login := entity.UserLogin{Email: req.Email, Password: req.Password}
+	if fields := utils.Validate(login); len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid login request", fields))
+	}
+
+	id, err := u.userService.Login(ctx, &entity.User{Email: login.Email, Password: login.Password})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &kanbanv1.LoginResponse{UserId: int64(id)}, nil
+}
+
+func (u *userGRPC) Register(ctx context.Context, req *kanbanv1.RegisterRequest) (*kanbanv1.User, error) {
+	register := entity.UserRegister{Fullname: req.Fullname, Email: req.Email, Password: req.Password}
+	if fields := utils.Validate(register); len(fields) > 0 {
+		return nil, grpcError(service.NewValidationError("invalid register request", fields))
+	}
+
+	user, err := u.userService.Register(ctx, &entity.User{
+		Fullname: register.Fullname,
+		Email:    register.Email,
+		Password: register.Password,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcUser(user), nil
+}
+
+func (u *userGRPC) GetMe(ctx context.Context, req *kanbanv1.GetMeRequest) (*kanbanv1.User, error) {
+	user, err := u.userService.GetUserById(ctx, grpcViewer(ctx))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if user.ID == 0 {
+		return nil, grpcError(service.ErrUserNotFound)
+	}
+	return grpcUser(user), nil
+}
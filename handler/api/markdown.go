@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type MarkdownAPI interface {
+	ExportMarkdown(w http.ResponseWriter, r *http.Request)
+}
+
+type markdownAPI struct {
+	markdownService service.MarkdownService
+}
+
+func NewMarkdownAPI(markdownService service.MarkdownService) *markdownAPI {
+	return &markdownAPI{markdownService}
+}
+
+// ExportMarkdown renders the board, or the column category_id, as Markdown.
+// completed=true, or a from or to date, renders the completed tasks
+// instead.
+func (m *markdownAPI) ExportMarkdown(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	query := r.URL.Query()
+	options := entity.MarkdownOptions{
+		Completed: query.Get("completed") == "true",
+		GroupBy:   query.Get("group_by"),
+	}
+
+	if query.Get("category_id") != "" {
+		options.CategoryID, err = strconv.Atoi(query.Get("category_id"))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid category id")
+			return
+		}
+	}
+
+	dates := []struct {
+		param string
+		value *time.Time
+	}{
+		{"from", &options.From},
+		{"to", &options.To},
+	}
+	for _, date := range dates {
+		if query.Get(date.param) == "" {
+			continue
+		}
+
+		*date.value, err = time.Parse(entity.DateLayout, query.Get(date.param))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid "+date.param+" date, expected YYYY-MM-DD")
+			return
+		}
+		options.Completed = true
+	}
+
+	markdown, err := m.markdownService.RenderBoard(r.Context(), userIdInt, options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(markdown))
+}
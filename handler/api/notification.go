@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type NotificationAPI interface {
+	WatchTask(w http.ResponseWriter, r *http.Request)
+	UnwatchTask(w http.ResponseWriter, r *http.Request)
+	GetNotifications(w http.ResponseWriter, r *http.Request)
+	CountUnread(w http.ResponseWriter, r *http.Request)
+	MarkRead(w http.ResponseWriter, r *http.Request)
+}
+
+type notificationAPI struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationAPI(notificationService service.NotificationService) *notificationAPI {
+	return &notificationAPI{notificationService}
+}
+
+func (n *notificationAPI) WatchTask(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	taskIdInt, _ := strconv.Atoi(r.URL.Query().Get("task_id"))
+
+	err = n.notificationService.WatchTask(r.Context(), userIdInt, taskIdInt)
+	if err != nil {
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userIdInt,
+		"task_id": taskIdInt,
+		"message": "success watch task",
+	})
+}
+
+func (n *notificationAPI) UnwatchTask(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	taskIdInt, _ := strconv.Atoi(r.URL.Query().Get("task_id"))
+
+	err = n.notificationService.UnwatchTask(r.Context(), userIdInt, taskIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userIdInt,
+		"task_id": taskIdInt,
+		"message": "success unwatch task",
+	})
+}
+
+func (n *notificationAPI) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, err := n.notificationService.GetNotifications(r.Context(), userIdInt, unreadOnly)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(notifications)
+}
+
+func (n *notificationAPI) CountUnread(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	count, err := n.notificationService.CountUnread(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userIdInt,
+		"unread":  count,
+	})
+}
+
+func (n *notificationAPI) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	// an empty body marks every notification as read
+	var req entity.NotificationReadRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid decode json")
+		return
+	}
+
+	err = n.notificationService.MarkRead(r.Context(), userIdInt, req.IDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userIdInt,
+		"message": "success mark notifications read",
+	})
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/utils"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// maxPatchTextLength matches the max=255 binding of the create requests.
+const maxPatchTextLength = 255
+
+var errUnsupportedPatchType = errors.New("unsupported content type, expected " + mergePatchContentType)
+
+// decodeMergePatch reads a JSON Merge Patch (RFC 7396) document into its
+// members, keeping null members so they can be told apart from absent ones.
+func decodeMergePatch(r *http.Request) (map[string]json.RawMessage, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			return nil, errUnsupportedPatchType
+		}
+	}
+
+	var members map[string]json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&members)
+	if err != nil || members == nil {
+		return nil, errors.New("invalid merge patch document")
+	}
+	return members, nil
+}
+
+// decodeTaskPatch reads the members of a task merge patch and reports every
+// invalid member as a field error.
+func decodeTaskPatch(members map[string]json.RawMessage) (entity.TaskPatch, []entity.FieldError) {
+	var patch entity.TaskPatch
+	var fields []entity.FieldError
+	invalid := func(field string, format string, args ...interface{}) {
+		fields = append(fields, entity.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range sortedMembers(members) {
+		raw := members[name]
+
+		switch name {
+		case "title":
+			var title string
+			if isNull(raw) || json.Unmarshal(raw, &title) != nil || strings.TrimSpace(title) == "" {
+				invalid(name, "must be a non-empty string")
+				continue
+			}
+			if utf8.RuneCountInString(title) > maxPatchTextLength {
+				invalid(name, "must be at most %d characters", maxPatchTextLength)
+				continue
+			}
+			patch.Title = &title
+		case "description":
+			var description string
+			if !isNull(raw) && json.Unmarshal(raw, &description) != nil {
+				invalid(name, "must be a string or null")
+				continue
+			}
+			patch.Description = &description
+		case "category_id":
+			var categoryId int
+			if isNull(raw) || json.Unmarshal(raw, &categoryId) != nil || categoryId <= 0 {
+				invalid(name, "must be a positive integer")
+				continue
+			}
+			patch.CategoryID = &categoryId
+		case "estimate":
+			var estimate float64
+			if !isNull(raw) && (json.Unmarshal(raw, &estimate) != nil || !entity.IsValidEstimate(estimate)) {
+				invalid(name, "must be a number from 0 to %d or null", entity.MaxTaskEstimate)
+				continue
+			}
+			patch.Estimate = &estimate
+		case "due_date":
+			var dueDate time.Time
+			if !isNull(raw) {
+				var value string
+				err := json.Unmarshal(raw, &value)
+				if err == nil {
+					dueDate, err = time.Parse(entity.DateLayout, value)
+				}
+				if err != nil {
+					invalid(name, "must be a date as YYYY-MM-DD or null")
+					continue
+				}
+			}
+			patch.DueDate = &dueDate
+		case "custom_fields":
+			// merged per field: {"<field id>": value}, null clears the value
+			var values map[string]interface{}
+			if isNull(raw) || json.Unmarshal(raw, &values) != nil {
+				invalid(name, "must be an object keyed by field id")
+				continue
+			}
+
+			for _, key := range sortedKeys(values) {
+				fieldId, err := strconv.Atoi(key)
+				if err != nil {
+					invalid(name+"."+key, "is not a field id")
+					continue
+				}
+				patch.CustomFields = append(patch.CustomFields, entity.CustomFieldValue{FieldID: fieldId, Value: values[key]})
+			}
+		default:
+			invalid(name, "is not a known field")
+		}
+	}
+
+	return patch, fields
+}
+
+// decodeCategoryPatch reads the members of a category merge patch and
+// reports every invalid member as a field error.
+func decodeCategoryPatch(members map[string]json.RawMessage) (entity.CategoryPatch, []entity.FieldError) {
+	var patch entity.CategoryPatch
+	var fields []entity.FieldError
+
+	for _, name := range sortedMembers(members) {
+		raw := members[name]
+
+		switch name {
+		case "type":
+			var categoryType string
+			if isNull(raw) || json.Unmarshal(raw, &categoryType) != nil || strings.TrimSpace(categoryType) == "" {
+				fields = append(fields, entity.FieldError{Field: name, Message: "must be a non-empty string"})
+				continue
+			}
+			if utf8.RuneCountInString(categoryType) > maxPatchTextLength {
+				fields = append(fields, entity.FieldError{Field: name, Message: fmt.Sprintf("must be at most %d characters", maxPatchTextLength)})
+				continue
+			}
+			patch.Type = &categoryType
+		default:
+			fields = append(fields, entity.FieldError{Field: name, Message: "is not a known field"})
+		}
+	}
+
+	return patch, fields
+}
+
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// sortedMembers keeps validation errors stable when several members are
+// invalid.
+func sortedMembers(members map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writePatchDecodeError reports a patch document that is not a JSON object
+// or has the wrong content type. Invalid members are validation errors.
+func writePatchDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUnsupportedPatchType) {
+		utils.WriteError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	utils.WriteError(w, http.StatusBadRequest, err.Error())
+}
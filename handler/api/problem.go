@@ -0,0 +1,40 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+var errorKindStatus = map[service.ErrorKind]int{
+	service.KindInvalid:            http.StatusBadRequest,
+	service.KindUnauthorized:       http.StatusUnauthorized,
+	service.KindNotFound:           http.StatusNotFound,
+	service.KindConflict:           http.StatusConflict,
+	service.KindPreconditionFailed: http.StatusPreconditionFailed,
+	service.KindValidation:         http.StatusUnprocessableEntity,
+}
+
+// writeError maps a service error to its status code and writes it as a
+// problem.
+func writeError(w http.ResponseWriter, err error) {
+	utils.WriteProblem(w, problemOf(err))
+}
+
+// problemOf describes err as a problem. Errors without a domain meaning are
+// logged and hidden behind a generic internal server error.
+func problemOf(err error) entity.Problem {
+	domainErr, ok := service.AsError(err)
+	if !ok || errorKindStatus[domainErr.Kind] == 0 {
+		log.Println(err.Error())
+		return entity.NewProblem(http.StatusInternalServerError, "error internal server")
+	}
+
+	problem := entity.NewProblem(errorKindStatus[domainErr.Kind], err.Error())
+	problem.Code = domainErr.Code
+	problem.Errors = domainErr.Fields
+	return problem
+}
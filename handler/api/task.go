@@ -2,20 +2,29 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/snykk/kanban-app/entity"
 	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
 )
 
 type TaskAPI interface {
 	GetTask(w http.ResponseWriter, r *http.Request)
+	SearchTasks(w http.ResponseWriter, r *http.Request)
 	CreateNewTask(w http.ResponseWriter, r *http.Request)
 	UpdateTask(w http.ResponseWriter, r *http.Request)
+	PatchTask(w http.ResponseWriter, r *http.Request)
 	DeleteTask(w http.ResponseWriter, r *http.Request)
 	UpdateTaskCategory(w http.ResponseWriter, r *http.Request)
+	BulkTasks(w http.ResponseWriter, r *http.Request)
 }
 
 type taskAPI struct {
@@ -30,74 +39,131 @@ func (t *taskAPI) GetTask(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value("id").(string)
 	userIdInt, _ := strconv.Atoi(userId)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
-	taskID := r.URL.Query().Get("task_id")
+	query := r.URL.Query()
+	taskID := query.Get("task_id")
 	taskIdInt, _ := strconv.Atoi(taskID)
 	if taskID == "" {
-		tasks, err := t.taskService.GetTasks(r.Context(), userIdInt)
+		filter, err := parseTaskFilter(query)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		page, err := t.taskService.GetTasksPage(r.Context(), userIdInt, filter)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			log.Println(err.Error())
-			json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+			writeError(w, err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(tasks)
+		json.NewEncoder(w).Encode(page)
 		return
 	}
 
 	task, err := t.taskService.GetTaskByID(r.Context(), taskIdInt)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
+		return
+	}
+
+	if task.ID == 0 || task.UserID != userIdInt {
+		writeError(w, service.ErrTaskNotFound)
 		return
 	}
+
+	setETag(w, task.Version)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(task)
 }
 
+func (t *taskAPI) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, _ := strconv.Atoi(userId)
+	if userId == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := entity.TaskSearchFilter{
+		Query: strings.TrimSpace(query.Get("q")),
+		Limit: entity.MaxSearchResults,
+	}
+	if filter.Query == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid search query")
+		return
+	}
+
+	var err error
+	if query.Get("category_id") != "" {
+		filter.CategoryID, err = strconv.Atoi(query.Get("category_id"))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid category id")
+			return
+		}
+	}
+
+	if query.Get("limit") != "" {
+		filter.Limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || filter.Limit < 1 || filter.Limit > entity.MaxSearchResults {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit, expected 1 to %d", entity.MaxSearchResults))
+			return
+		}
+	}
+
+	results, err := t.taskService.SearchTasks(r.Context(), userIdInt, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
+
 func (t *taskAPI) CreateNewTask(w http.ResponseWriter, r *http.Request) {
 	var task entity.TaskRequest
 
 	err := json.NewDecoder(r.Body).Decode(&task)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid task request"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid task request")
 		return
 	}
 
-	if task.Title == "" || task.CategoryID == 0 || task.Description == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid task request"))
+	// a new task needs a category, an update keeps the current one
+	var categoryErr []entity.FieldError
+	if task.CategoryID == 0 {
+		categoryErr = append(categoryErr, entity.FieldError{Field: "category_id", Message: "is required"})
+	}
+	dueDate, dueDateErr := parseDueDate(task.DueDate)
+	if !validateRequest(w, task, "invalid task request", append(categoryErr, dueDateErr...)...) {
 		return
 	}
 
 	userId := r.Context().Value("id").(string)
 	userIdInt, _ := strconv.Atoi(userId)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
 	entityTask := entity.Task{
-		Title:       task.Title,
-		Description: task.Description,
-		CategoryID:  task.CategoryID,
-		UserID:      userIdInt,
+		Title:        task.Title,
+		Description:  task.Description,
+		CategoryID:   task.CategoryID,
+		UserID:       userIdInt,
+		Estimate:     task.Estimate,
+		DueDate:      dueDate,
+		CustomFields: task.CustomFields,
 	}
 	createdTask, err := t.taskService.StoreTask(r.Context(), &entityTask)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -113,19 +179,21 @@ func (t *taskAPI) CreateNewTask(w http.ResponseWriter, r *http.Request) {
 func (t *taskAPI) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value("id").(string)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
 	taskID := r.URL.Query().Get("task_id")
 	taskIdInt, _ := strconv.Atoi(taskID)
 	userIdInt, _ := strconv.Atoi(userId)
-	err := t.taskService.DeleteTask(r.Context(), taskIdInt)
+	err := t.taskService.DeleteTask(r.Context(), userIdInt, taskIdInt, version)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -144,39 +212,46 @@ func (t *taskAPI) UpdateTask(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewDecoder(r.Body).Decode(&task)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid decode json"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid decode json")
 		return
 	}
 
-	if task.Description == "" || task.Title == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid task request"))
+	dueDate, dueDateErr := parseDueDate(task.DueDate)
+	if !validateRequest(w, task, "invalid task request", dueDateErr...) {
 		return
 	}
 
 	userId := r.Context().Value("id").(string)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	userIdInt, _ := strconv.Atoi(userId)
 	entityTask := entity.Task{
-		ID:          taskIdInt,
-		Title:       task.Title,
-		Description: task.Description,
-		CategoryID:  task.CategoryID,
+		ID:           taskIdInt,
+		Title:        task.Title,
+		Description:  task.Description,
+		CategoryID:   task.CategoryID,
+		UserID:       userIdInt,
+		Estimate:     task.Estimate,
+		DueDate:      dueDate,
+		CustomFields: task.CustomFields,
+		Version:      version,
 	}
 	updatedTask, err := t.taskService.UpdateTask(r.Context(), &entityTask)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
+	setETag(w, updatedTask.Version)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"user_id": updatedTask.UserID,
@@ -186,14 +261,57 @@ func (t *taskAPI) UpdateTask(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (t *taskAPI) PatchTask(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, _ := strconv.Atoi(userId)
+	if userId == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	taskIdInt, _ := strconv.Atoi(r.URL.Query().Get("task_id"))
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := decodeMergePatch(r)
+	if err != nil {
+		writePatchDecodeError(w, err)
+		return
+	}
+
+	patch, fields := decodeTaskPatch(members)
+	if len(fields) > 0 {
+		writeError(w, service.NewValidationError("invalid task patch", fields))
+		return
+	}
+
+	patch.Version = version
+
+	task, err := t.taskService.PatchTask(r.Context(), userIdInt, taskIdInt, patch)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, task.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
 func (t *taskAPI) UpdateTaskCategory(w http.ResponseWriter, r *http.Request) {
 	var task entity.TaskCategoryRequest
 
 	err := json.NewDecoder(r.Body).Decode(&task)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid decode json"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid decode json")
+		return
+	}
+
+	if !validateRequest(w, task, "invalid task category request") {
 		return
 	}
 
@@ -201,26 +319,26 @@ func (t *taskAPI) UpdateTaskCategory(w http.ResponseWriter, r *http.Request) {
 
 	idLogin, err := strconv.Atoi(userId.(string))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
-	var updateTask = entity.Task{
-		ID:         task.ID,
-		CategoryID: task.CategoryID,
-		UserID:     int(idLogin),
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
 	}
 
-	_, err = t.taskService.UpdateTask(r.Context(), &updateTask)
+	// a patch leaves the other fields, the estimate included, as they are
+	patch := entity.TaskPatch{CategoryID: &task.CategoryID, Version: version}
+
+	updatedTask, err := t.taskService.PatchTask(r.Context(), idLogin, task.ID, patch)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
+	setETag(w, updatedTask.Version)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"user_id": userId,
@@ -228,3 +346,130 @@ func (t *taskAPI) UpdateTaskCategory(w http.ResponseWriter, r *http.Request) {
 		"message": "success update task category",
 	})
 }
+
+func (t *taskAPI) BulkTasks(w http.ResponseWriter, r *http.Request) {
+	var req entity.BulkTaskRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid bulk request")
+		return
+	}
+
+	if !validateRequest(w, req, "invalid bulk request") {
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, _ := strconv.Atoi(userId)
+	if userId == "" {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	response, err := t.taskService.BulkTasks(r.Context(), userIdInt, req)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	case errors.Is(err, service.ErrBulkRejected):
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(response)
+	default:
+		writeError(w, err)
+	}
+}
+
+// parseDueDate reads the due date of a task request, empty meaning none.
+func parseDueDate(value string) (*time.Time, []entity.FieldError) {
+	if value == "" {
+		return nil, nil
+	}
+
+	dueDate, err := time.Parse(entity.DateLayout, value)
+	if err != nil {
+		return nil, []entity.FieldError{{Field: "due_date", Message: "must be a date as YYYY-MM-DD"}}
+	}
+	return &dueDate, nil
+}
+
+// parseTaskFilter reads the pagination, filter and sort parameters of the
+// task listing.
+func parseTaskFilter(query url.Values) (entity.TaskFilter, error) {
+	filter := entity.TaskFilter{
+		Query:   strings.TrimSpace(query.Get("q")),
+		Sort:    query.Get("sort"),
+		Page:    1,
+		PerPage: entity.DefaultTaskPerPage,
+	}
+
+	filter.Archived = query.Get("archived") == "true"
+
+	var err error
+	if query.Get("page") != "" {
+		filter.Page, err = strconv.Atoi(query.Get("page"))
+		if err != nil || filter.Page < 1 {
+			return filter, errors.New("invalid page")
+		}
+	}
+
+	if query.Get("per_page") != "" {
+		filter.PerPage, err = strconv.Atoi(query.Get("per_page"))
+		if err != nil || filter.PerPage < 1 || filter.PerPage > entity.MaxTaskPerPage {
+			return filter, fmt.Errorf("invalid per_page, expected 1 to %d", entity.MaxTaskPerPage)
+		}
+	}
+
+	if query.Get("category_id") != "" {
+		filter.CategoryID, err = strconv.Atoi(query.Get("category_id"))
+		if err != nil {
+			return filter, errors.New("invalid category id")
+		}
+	}
+
+	if filter.Sort != "" {
+		if _, ok := entity.TaskSortColumns[filter.Sort]; !ok {
+			return filter, errors.New("invalid sort key")
+		}
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, errors.New("invalid order, expected asc or desc")
+	}
+
+	dates := []struct {
+		param string
+		value *time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"updated_from", &filter.UpdatedFrom},
+		{"updated_to", &filter.UpdatedTo},
+	}
+	for _, date := range dates {
+		if query.Get(date.param) == "" {
+			continue
+		}
+
+		*date.value, err = time.Parse(entity.DateLayout, query.Get(date.param))
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s date, expected YYYY-MM-DD", date.param)
+		}
+	}
+
+	if !filter.CreatedTo.IsZero() && filter.CreatedTo.Before(filter.CreatedFrom) ||
+		!filter.UpdatedTo.IsZero() && filter.UpdatedTo.Before(filter.UpdatedFrom) {
+		return filter, errors.New("invalid date range")
+	}
+
+	filter.CustomField.FieldID, _ = strconv.Atoi(query.Get("field_id"))
+	filter.CustomField.Value = query.Get("field_value")
+	filter.CustomField.SortID, _ = strconv.Atoi(query.Get("sort_field_id"))
+
+	return filter, nil
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// maxTaskCSVImportSize bounds the CSV import body.
+const maxTaskCSVImportSize = 5 << 20
+
+type TaskCSVAPI interface {
+	ExportTasksCSV(w http.ResponseWriter, r *http.Request)
+	ImportTasksCSV(w http.ResponseWriter, r *http.Request)
+}
+
+type taskCSVAPI struct {
+	taskCSVService service.TaskCSVService
+}
+
+func NewTaskCSVAPI(taskCSVService service.TaskCSVService) *taskCSVAPI {
+	return &taskCSVAPI{taskCSVService}
+}
+
+// ExportTasksCSV takes the filters and sort of the task listing, and the
+// comma separated columns to write.
+func (t *taskCSVAPI) ExportTasksCSV(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	filter, err := parseTaskFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// custom field filters need the field, the export does not support them
+	filter.CustomField = entity.CustomFieldFilter{}
+
+	options := entity.TaskCSVExportOptions{Filter: filter}
+	if columns := r.URL.Query().Get("columns"); columns != "" {
+		for _, column := range strings.Split(columns, ",") {
+			options.Columns = append(options.Columns, strings.TrimSpace(column))
+		}
+	}
+
+	records, err := t.taskCSVService.ExportTasks(r.Context(), userIdInt, options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("tasks-%d-%s.csv", userIdInt, time.Now().UTC().Format(entity.DateLayout))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.WriteAll(records)
+	if err := writer.Error(); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func (t *taskCSVAPI) ImportTasksCSV(w http.ResponseWriter, r *http.Request) {
+	var req entity.TaskCSVImportRequest
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaskCSVImportSize)).Decode(&req)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid decode json")
+		return
+	}
+
+	if !validateRequest(w, req, "invalid csv import request") {
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	result, err := t.taskCSVService.ImportTasks(r.Context(), userIdInt, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
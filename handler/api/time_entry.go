@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type TimeEntryAPI interface {
+	StartTimer(w http.ResponseWriter, r *http.Request)
+	StopTimer(w http.ResponseWriter, r *http.Request)
+	CreateTimeEntry(w http.ResponseWriter, r *http.Request)
+	GetTimeReport(w http.ResponseWriter, r *http.Request)
+}
+
+type timeEntryAPI struct {
+	timeEntryService service.TimeEntryService
+}
+
+func NewTimeEntryAPI(timeEntryService service.TimeEntryService) *timeEntryAPI {
+	return &timeEntryAPI{timeEntryService}
+}
+
+func (t *timeEntryAPI) StartTimer(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	taskIdInt, err := strconv.Atoi(r.URL.Query().Get("task_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid task id")
+		return
+	}
+
+	entry, err := t.timeEntryService.StartTimer(r.Context(), userIdInt, taskIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":       userIdInt,
+		"task_id":       entry.TaskID,
+		"time_entry_id": entry.ID,
+		"started_at":    entry.StartedAt,
+		"message":       "success start timer",
+	})
+}
+
+func (t *timeEntryAPI) StopTimer(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	entry, err := t.timeEntryService.StopTimer(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":       userIdInt,
+		"task_id":       entry.TaskID,
+		"time_entry_id": entry.ID,
+		"duration":      entry.Duration,
+		"message":       "success stop timer",
+	})
+}
+
+func (t *timeEntryAPI) CreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	var entry entity.TimeEntryRequest
+
+	err := json.NewDecoder(r.Body).Decode(&entry)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid time entry request")
+		return
+	}
+
+	if !validateRequest(w, entry, "invalid time entry request") {
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	entityEntry := entity.TimeEntry{
+		TaskID:    entry.TaskID,
+		UserID:    userIdInt,
+		StartedAt: entry.StartedAt,
+		EndedAt:   &entry.EndedAt,
+		Note:      entry.Note,
+	}
+	createdEntry, err := t.timeEntryService.StoreTimeEntry(r.Context(), &entityEntry)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":       userIdInt,
+		"task_id":       createdEntry.TaskID,
+		"time_entry_id": createdEntry.ID,
+		"duration":      createdEntry.Duration,
+		"message":       "success create time entry",
+	})
+}
+
+func (t *timeEntryAPI) GetTimeReport(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	query := r.URL.Query()
+	var filter entity.TimeReportFilter
+
+	if query.Get("task_id") != "" {
+		filter.TaskID, err = strconv.Atoi(query.Get("task_id"))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid task id")
+			return
+		}
+	}
+
+	if query.Get("user_id") != "" {
+		filter.UserID, err = strconv.Atoi(query.Get("user_id"))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+			return
+		}
+	}
+
+	if query.Get("from") != "" {
+		filter.From, err = time.Parse(entity.DateLayout, query.Get("from"))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid from date, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	if query.Get("to") != "" {
+		filter.To, err = time.Parse(entity.DateLayout, query.Get("to"))
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid to date, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	report, err := t.timeEntryService.GetTimeReport(r.Context(), userIdInt, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
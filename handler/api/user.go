@@ -10,6 +10,7 @@ import (
 
 	"github.com/snykk/kanban-app/entity"
 	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
 )
 
 type UserAPI interface {
@@ -35,9 +36,7 @@ func (t *userAPI) GetUserById(w http.ResponseWriter, r *http.Request) {
 
 	user, err := t.userService.GetUserById(r.Context(), userIdInt)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -49,14 +48,11 @@ func (u *userAPI) Login(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewDecoder(r.Body).Decode(&user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid decode json"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid decode json")
 		return
 	}
-	if user.Email == "" || user.Password == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("email or password is empty"))
+	if !validateRequest(w, user, "invalid login request") {
 		return
 	}
 
@@ -66,9 +62,7 @@ func (u *userAPI) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	id, err := u.userService.Login(r.Context(), &entityUser)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -93,21 +87,12 @@ func (u *userAPI) Register(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewDecoder(r.Body).Decode(&user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid decode json"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid decode json")
 		return
 	}
 
-	if user.Email == "" || user.Password == "" || user.Fullname == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("register data is empty"))
-		return
-	}
-
-	if len(user.Password) < 5 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("password must be atleast 6 character"))
+	if !validateRequest(w, user, "invalid register request") {
 		return
 	}
 
@@ -119,9 +104,7 @@ func (u *userAPI) Register(w http.ResponseWriter, r *http.Request) {
 
 	newEntityUser, err := u.userService.Register(r.Context(), &entityUser)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -147,8 +130,7 @@ func (u *userAPI) Delete(w http.ResponseWriter, r *http.Request) {
 	userId := r.URL.Query().Get("user_id")
 
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("user_id is empty"))
+		utils.WriteError(w, http.StatusBadRequest, "user_id is empty")
 		return
 	}
 
@@ -156,9 +138,7 @@ func (u *userAPI) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err := u.userService.Delete(r.Context(), int(deleteUserId))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println(err.Error())
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("error internal server"))
+		writeError(w, err)
 		return
 	}
 
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// validateRequest checks the binding tags of req, plus any extra field
+// errors found by the handler, and writes all of them at once. It reports
+// whether req is valid.
+func validateRequest(w http.ResponseWriter, req interface{}, message string, extra ...entity.FieldError) bool {
+	fields := append(utils.Validate(req), extra...)
+	if len(fields) == 0 {
+		return true
+	}
+
+	writeError(w, service.NewValidationError(message, fields))
+	return false
+}
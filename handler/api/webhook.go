@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+type WebhookAPI interface {
+	GetWebhooks(w http.ResponseWriter, r *http.Request)
+	CreateNewWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	GetDeliveries(w http.ResponseWriter, r *http.Request)
+	Redeliver(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookAPI struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookAPI(webhookService service.WebhookService) *webhookAPI {
+	return &webhookAPI{webhookService}
+}
+
+func (h *webhookAPI) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(r.Context(), userIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+func (h *webhookAPI) CreateNewWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook entity.WebhookRequest
+
+	err := json.NewDecoder(r.Body).Decode(&webhook)
+	if err != nil {
+		log.Println(err.Error())
+		utils.WriteError(w, http.StatusBadRequest, "invalid webhook request")
+		return
+	}
+
+	if !validateRequest(w, webhook, "invalid webhook request") {
+		return
+	}
+
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	entityWebhook := entity.Webhook{
+		UserID: userIdInt,
+		URL:    webhook.URL,
+		Secret: webhook.Secret,
+		Events: webhook.Events,
+	}
+	createdWebhook, err := h.webhookService.StoreWebhook(r.Context(), &entityWebhook)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":    userIdInt,
+		"webhook_id": createdWebhook.ID,
+		"message":    "success create new webhook",
+	})
+}
+
+func (h *webhookAPI) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	webhookIdInt, _ := strconv.Atoi(r.URL.Query().Get("webhook_id"))
+
+	err = h.webhookService.DeleteWebhook(r.Context(), userIdInt, webhookIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":    userIdInt,
+		"webhook_id": webhookIdInt,
+		"message":    "success delete webhook",
+	})
+}
+
+func (h *webhookAPI) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	webhookIdInt, _ := strconv.Atoi(r.URL.Query().Get("webhook_id"))
+
+	deliveries, err := h.webhookService.GetDeliveries(r.Context(), userIdInt, webhookIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func (h *webhookAPI) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	webhookIdInt, _ := strconv.Atoi(r.URL.Query().Get("webhook_id"))
+	deliveryIdInt, _ := strconv.Atoi(r.URL.Query().Get("delivery_id"))
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), userIdInt, webhookIdInt, deliveryIdInt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
@@ -2,14 +2,13 @@ package web
 
 import (
 	"embed"
-	"encoding/json"
 	"log"
 	"net/http"
 	"path"
 	"text/template"
 
 	"github.com/snykk/kanban-app/client"
-	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/utils"
 )
 
 type DashboardWeb interface {
@@ -17,23 +16,24 @@ type DashboardWeb interface {
 }
 
 type dashboardWeb struct {
-	categoryClient client.CategoryClient
-	userClient     client.UserClient
-	embed          embed.FS
+	categoryClient     client.CategoryClient
+	userClient         client.UserClient
+	notificationClient client.NotificationClient
+	embed              embed.FS
 }
 
-func NewDashboardWeb(catClient client.CategoryClient, uClient client.UserClient, emb embed.FS) *dashboardWeb {
+func NewDashboardWeb(catClient client.CategoryClient, uClient client.UserClient, nClient client.NotificationClient, emb embed.FS) *dashboardWeb {
 	return &dashboardWeb{
-		categoryClient: catClient,
-		userClient:     uClient,
-		embed:          emb}
+		categoryClient:     catClient,
+		userClient:         uClient,
+		notificationClient: nClient,
+		embed:              emb}
 }
 
 func (d *dashboardWeb) Dashboard(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value("id").(string)
 	if userId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(entity.NewErrorResponse("invalid user id"))
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
 		return
 	}
 
@@ -51,9 +51,22 @@ func (d *dashboardWeb) Dashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// a failing counter should not take the whole dashboard down
+	unreadCount, err := d.notificationClient.GetUnreadCount(userId)
+	if err != nil {
+		log.Println("error get notification count: ", err.Error())
+	}
+
+	var boardEstimate float64
+	if len(categories) > 0 {
+		boardEstimate = categories[0].BoardEstimate
+	}
+
 	var dataTemplate = map[string]interface{}{
-		"categories": categories,
-		"users":      users,
+		"categories":    categories,
+		"users":         users,
+		"boardEstimate": boardEstimate,
+		"unreadCount":   unreadCount,
 	}
 
 	var getIndexByCategoryId = func(catId int) int {
@@ -67,6 +80,7 @@ func (d *dashboardWeb) Dashboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var funcMap = template.FuncMap{
+		"mentions": renderMentions,
 		"categoryInc": func(categoryId int) int {
 			idx := getIndexByCategoryId(categoryId)
 
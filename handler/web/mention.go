@@ -0,0 +1,37 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// renderMentions escapes text for HTML and turns every resolved @mention
+// into a highlighted mailto link. Unresolved tokens are left as plain text.
+func renderMentions(text string, mentions []entity.Mention) string {
+	emailByToken := make(map[string]string, len(mentions))
+	for _, mention := range mentions {
+		emailByToken[mention.Token] = mention.Email
+	}
+
+	escaped := template.HTMLEscapeString(text)
+
+	return utils.MentionPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+		at := strings.Index(match, "@")
+		prefix, token := match[:at], strings.TrimRight(match[at+1:], ".")
+		suffix := match[at+1+len(token):]
+
+		email, ok := emailByToken[token]
+		if !ok {
+			return match
+		}
+
+		// the email comes from user data, so it is escaped like the text
+		href := template.HTMLEscapeString("mailto:" + url.PathEscape(email))
+		return fmt.Sprintf(`%s<a href="%s" class="px-1 font-semibold text-indigo-700 bg-indigo-100 rounded hover:underline">@%s</a>%s`, prefix, href, token, suffix)
+	})
+}
@@ -18,6 +18,7 @@ type ModifyWeb interface {
 
 	UpdateTask(w http.ResponseWriter, r *http.Request)
 	UpdateTaskProcess(w http.ResponseWriter, r *http.Request)
+	AddCommentProcess(w http.ResponseWriter, r *http.Request)
 
 	DeleteTask(w http.ResponseWriter, r *http.Request)
 	DeleteCategory(w http.ResponseWriter, r *http.Request)
@@ -26,11 +27,12 @@ type ModifyWeb interface {
 type modifyWeb struct {
 	taskClient     client.TaskClient
 	categoryClient client.CategoryClient
+	commentClient  client.CommentClient
 	embed          embed.FS
 }
 
-func NewModifyWeb(tC client.TaskClient, cC client.CategoryClient, embed embed.FS) *modifyWeb {
-	return &modifyWeb{tC, cC, embed}
+func NewModifyWeb(tC client.TaskClient, cC client.CategoryClient, coC client.CommentClient, embed embed.FS) *modifyWeb {
+	return &modifyWeb{tC, cC, coC, embed}
 }
 
 func (a *modifyWeb) AddTask(w http.ResponseWriter, r *http.Request) {
@@ -57,9 +59,10 @@ func (a *modifyWeb) AddTaskProcess(w http.ResponseWriter, r *http.Request) {
 
 	title := r.FormValue("title")
 	description := r.FormValue("description")
+	estimate := r.FormValue("estimate")
 	category := r.URL.Query().Get("category")
 
-	respCode, err := a.taskClient.CreateTask(title, description, category, userId.(string))
+	respCode, err := a.taskClient.CreateTask(title, description, estimate, category, userId.(string))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -115,24 +118,44 @@ func (a *modifyWeb) AddCategoryProcess(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *modifyWeb) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	taskId := r.URL.Query().Get("task_id")
+	a.renderUpdateTask(w, r, r.URL.Query().Get("task_id"), nil)
+}
 
+// renderUpdateTask shows the update form with the latest task. A non-nil
+// conflict holds the edit that was rejected because the task changed.
+func (a *modifyWeb) renderUpdateTask(w http.ResponseWriter, r *http.Request, taskId string, conflict map[string]string) {
 	task, err := a.taskClient.GetTaskById(taskId, r.Context().Value("id").(string))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// ignore this
-	_ = task
-	//
+	comments, err := a.commentClient.GetComments(taskId, r.Context().Value("id").(string))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var dataTemplate = map[string]interface{}{
+		"task":     task,
+		"comments": comments,
+		"conflict": conflict,
+	}
+
+	var funcMap = template.FuncMap{
+		"mentions": renderMentions,
+	}
 
 	var filepath = path.Join("views", "main", "update-task.html")
 	var header = path.Join("views", "general", "header.html")
 
-	var tmpl = template.Must(template.ParseFS(a.embed, filepath, header))
+	var tmpl = template.Must(template.New("").Funcs(funcMap).ParseFS(a.embed, filepath, header))
 
-	err = tmpl.Execute(w, task)
+	if conflict != nil {
+		w.WriteHeader(http.StatusConflict)
+	}
+
+	err = tmpl.ExecuteTemplate(w, "update-task.html", dataTemplate)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -146,13 +169,24 @@ func (a *modifyWeb) UpdateTaskProcess(w http.ResponseWriter, r *http.Request) {
 	if categoryId == "" {
 		title := r.FormValue("title")
 		description := r.FormValue("description")
+		estimate := r.FormValue("estimate")
+		version := r.FormValue("version")
 
-		respCode, err := a.taskClient.UpdateTask(taskId, title, description, r.Context().Value("id").(string))
+		respCode, err := a.taskClient.UpdateTask(taskId, title, description, estimate, version, r.Context().Value("id").(string))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		if respCode == http.StatusPreconditionFailed {
+			a.renderUpdateTask(w, r, taskId, map[string]string{
+				"title":       title,
+				"description": description,
+				"estimate":    estimate,
+			})
+			return
+		}
+
 		if respCode == 200 {
 			http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 		} else {
@@ -170,6 +204,19 @@ func (a *modifyWeb) UpdateTaskProcess(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (a *modifyWeb) AddCommentProcess(w http.ResponseWriter, r *http.Request) {
+	taskId := r.URL.Query().Get("task_id")
+	body := r.FormValue("body")
+
+	_, err := a.commentClient.CreateComment(taskId, body, r.Context().Value("id").(string))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/task/update?task_id="+taskId, http.StatusSeeOther)
+}
+
 func (a *modifyWeb) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	taskId := r.URL.Query().Get("task_id")
 
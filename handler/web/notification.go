@@ -0,0 +1,57 @@
+package web
+
+import (
+	"embed"
+	"log"
+	"net/http"
+	"path"
+	"text/template"
+
+	"github.com/snykk/kanban-app/client"
+)
+
+type NotificationWeb interface {
+	Notifications(w http.ResponseWriter, r *http.Request)
+	MarkAllRead(w http.ResponseWriter, r *http.Request)
+}
+
+type notificationWeb struct {
+	notificationClient client.NotificationClient
+	embed              embed.FS
+}
+
+func NewNotificationWeb(notificationClient client.NotificationClient, embed embed.FS) *notificationWeb {
+	return &notificationWeb{notificationClient, embed}
+}
+
+func (n *notificationWeb) Notifications(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+
+	notifications, err := n.notificationClient.GetNotifications(userId)
+	if err != nil {
+		log.Println("error get notification data: ", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var filepath = path.Join("views", "main", "notifications.html")
+	var header = path.Join("views", "general", "header.html")
+
+	var tmpl = template.Must(template.ParseFS(n.embed, filepath, header))
+
+	err = tmpl.Execute(w, notifications)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (n *notificationWeb) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	_, err := n.notificationClient.MarkAllRead(r.Context().Value("id").(string))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
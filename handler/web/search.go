@@ -0,0 +1,79 @@
+package web
+
+import (
+	"embed"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/snykk/kanban-app/client"
+	"github.com/snykk/kanban-app/entity"
+)
+
+type SearchWeb interface {
+	Search(w http.ResponseWriter, r *http.Request)
+}
+
+type searchWeb struct {
+	taskClient     client.TaskClient
+	categoryClient client.CategoryClient
+	embed          embed.FS
+}
+
+func NewSearchWeb(taskClient client.TaskClient, categoryClient client.CategoryClient, embed embed.FS) *searchWeb {
+	return &searchWeb{taskClient, categoryClient, embed}
+}
+
+func (s *searchWeb) Search(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("id").(string)
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	categoryId := r.URL.Query().Get("category_id")
+
+	categories, err := s.categoryClient.GetCategories(userId)
+	if err != nil {
+		log.Println("error get category data: ", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var results entity.TaskSearchResponse
+	if query != "" {
+		results, err = s.taskClient.SearchTasks(query, categoryId, userId)
+		if err != nil {
+			log.Println("error search task: ", err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var dataTemplate = map[string]interface{}{
+		"query":      query,
+		"categoryId": categoryId,
+		"categories": categories,
+		"results":    results.Results,
+	}
+
+	var funcMap = template.FuncMap{
+		"snippet": renderSnippet,
+	}
+
+	var filepath = path.Join("views", "main", "search.html")
+	var header = path.Join("views", "general", "header.html")
+
+	var tmpl = template.Must(template.New("").Funcs(funcMap).ParseFS(s.embed, filepath, header))
+
+	err = tmpl.ExecuteTemplate(w, "search.html", dataTemplate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderSnippet escapes a search snippet for HTML while keeping the <mark>
+// highlights added by the database.
+func renderSnippet(snippet string) string {
+	escaped := template.HTMLEscapeString(snippet)
+	return strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>").Replace(escaped)
+}
@@ -4,32 +4,51 @@ import (
 	"embed"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/snykk/kanban-app/client"
 	"github.com/snykk/kanban-app/config"
 	"github.com/snykk/kanban-app/handler/api"
 	"github.com/snykk/kanban-app/handler/web"
 	"github.com/snykk/kanban-app/middleware"
+	kanbanv1 "github.com/snykk/kanban-app/proto/kanban/v1"
 	"github.com/snykk/kanban-app/repository"
 	"github.com/snykk/kanban-app/service"
 
 	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
 type APIHandler struct {
-	UserAPIHandler     api.UserAPI
-	TaskAPIHandler     api.TaskAPI
-	CategoryAPIHandler api.CategoryAPI
+	UserAPIHandler         api.UserAPI
+	TaskAPIHandler         api.TaskAPI
+	CategoryAPIHandler     api.CategoryAPI
+	TimeEntryAPIHandler    api.TimeEntryAPI
+	CustomFieldAPIHandler  api.CustomFieldAPI
+	NotificationAPIHandler api.NotificationAPI
+	CommentAPIHandler      api.CommentAPI
+	EventAPIHandler        api.EventAPI
+	CollabAPIHandler       api.CollabAPI
+	WebhookAPIHandler      api.WebhookAPI
+	GraphQLAPIHandler      api.GraphQLAPI
+	BoardAPIHandler        api.BoardAPI
+	TaskCSVAPIHandler      api.TaskCSVAPI
+	MarkdownAPIHandler     api.MarkdownAPI
+	CalendarAPIHandler     api.CalendarAPI
 }
 
 type ClientHandler struct {
-	AuthWeb      web.AuthWeb
-	DashboardWeb web.DashboardWeb
-	ModifyWeb    web.ModifyWeb
-	HomeWeb      web.HomeWeb
+	AuthWeb         web.AuthWeb
+	DashboardWeb    web.DashboardWeb
+	ModifyWeb       web.ModifyWeb
+	HomeWeb         web.HomeWeb
+	NotificationWeb web.NotificationWeb
+	SearchWeb       web.SearchWeb
 }
 
 //go:embed views/*
@@ -43,51 +62,127 @@ func init() {
 }
 
 func main() {
-	wg := sync.WaitGroup{}
+	if len(os.Args) > 1 {
+		os.Exit(RunCommand(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	err := repository.ConnectDB()
+	if err != nil {
+		panic(err)
+	}
 
-		mux := http.NewServeMux()
+	db := repository.GetDBConnection()
 
-		err := repository.ConnectDB()
-		if err != nil {
-			panic(err)
-		}
+	mux := http.NewServeMux()
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(middleware.GRPCAuth),
+		grpc.StreamInterceptor(middleware.GRPCStreamAuth),
+	)
+
+	mux = RunServer(db, mux, grpcServer)
+	mux = RunClient(mux, Resources)
 
-		db := repository.GetDBConnection()
+	wg := sync.WaitGroup{}
 
-		mux = RunServer(db, mux)
-		mux = RunClient(mux, Resources)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
 
 		fmt.Printf("Server is running on port %d", config.AppConfig.Port)
-		err = http.ListenAndServe(fmt.Sprintf(":%d", config.AppConfig.Port), mux)
+		err := http.ListenAndServe(fmt.Sprintf(":%d", config.AppConfig.Port), mux)
 		if err != nil {
 			panic(err)
 		}
 	}()
 
+	// the gRPC API is only served when it has a port
+	if config.AppConfig.GRPCPort != 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			lis, err := net.Listen("tcp", fmt.Sprintf(":%d", config.AppConfig.GRPCPort))
+			if err != nil {
+				panic(err)
+			}
+
+			fmt.Printf("gRPC server is running on port %d", config.AppConfig.GRPCPort)
+			err = grpcServer.Serve(lis)
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
+
 	wg.Wait()
 }
 
-func RunServer(db *gorm.DB, mux *http.ServeMux) *http.ServeMux {
+// RunServer registers the HTTP API on mux and the gRPC API on grpcServer,
+// both backed by the same services.
+func RunServer(db *gorm.DB, mux *http.ServeMux, grpcServer *grpc.Server) *http.ServeMux {
 	userRepo := repository.NewUserRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	timeEntryRepo := repository.NewTimeEntryRepository(db)
+	customFieldRepo := repository.NewCustomFieldRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	boardRepo := repository.NewBoardRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+
+	eventBus := service.NewEventBus()
 
 	userService := service.NewUserService(userRepo, categoryRepo)
-	taskService := service.NewTaskService(taskRepo, categoryRepo)
-	categoryService := service.NewCategoryService(categoryRepo, taskRepo)
+	taskService := service.NewTaskService(taskRepo, categoryRepo, customFieldRepo, notificationRepo, userRepo, eventBus)
+	categoryService := service.NewCategoryService(categoryRepo, taskRepo, customFieldRepo, notificationRepo, eventBus)
+	timeEntryService := service.NewTimeEntryService(timeEntryRepo, taskRepo)
+	customFieldService := service.NewCustomFieldService(customFieldRepo)
+	notificationService := service.NewNotificationService(notificationRepo, taskRepo)
+	commentService := service.NewCommentService(commentRepo, taskRepo, userRepo, notificationRepo)
+	collabHub := service.NewCollabHub(eventBus, taskRepo)
+	webhookService := service.NewWebhookService(webhookRepo, eventBus, time.Duration(config.AppConfig.WebhookRetryBaseSeconds)*time.Second, config.AppConfig.WebhookAllowPrivateNetworks)
+	boardService := service.NewBoardService(boardRepo)
+	taskCSVService := service.NewTaskCSVService(taskRepo, categoryRepo, eventBus)
+	markdownService := service.NewMarkdownService(taskRepo, categoryRepo, userRepo)
+	calendarService := service.NewCalendarService(calendarRepo, taskRepo, categoryRepo)
+	loaderService := service.NewLoaderService(taskRepo, categoryRepo, userRepo)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, time.Duration(config.AppConfig.IdempotencyWindowHours)*time.Hour)
+	idempotent := middleware.Idempotency(idempotencyService)
 
 	userAPIHandler := api.NewUserAPI(userService)
 	taskAPIHandler := api.NewTaskAPI(taskService)
 	categoryAPIHandler := api.NewCategoryAPI(categoryService)
+	timeEntryAPIHandler := api.NewTimeEntryAPI(timeEntryService)
+	customFieldAPIHandler := api.NewCustomFieldAPI(customFieldService)
+	notificationAPIHandler := api.NewNotificationAPI(notificationService)
+	commentAPIHandler := api.NewCommentAPI(commentService)
+	eventAPIHandler := api.NewEventAPI(eventBus)
+	collabAPIHandler := api.NewCollabAPI(collabHub, userService)
+	webhookAPIHandler := api.NewWebhookAPI(webhookService)
+	graphQLAPIHandler := api.NewGraphQLAPI(taskService, categoryService, userService, loaderService)
+	boardAPIHandler := api.NewBoardAPI(boardService)
+	taskCSVAPIHandler := api.NewTaskCSVAPI(taskCSVService)
+	markdownAPIHandler := api.NewMarkdownAPI(markdownService)
+	calendarAPIHandler := api.NewCalendarAPI(calendarService)
 
 	apiHandler := APIHandler{
-		UserAPIHandler:     userAPIHandler,
-		TaskAPIHandler:     taskAPIHandler,
-		CategoryAPIHandler: categoryAPIHandler,
+		UserAPIHandler:         userAPIHandler,
+		TaskAPIHandler:         taskAPIHandler,
+		CategoryAPIHandler:     categoryAPIHandler,
+		TimeEntryAPIHandler:    timeEntryAPIHandler,
+		CustomFieldAPIHandler:  customFieldAPIHandler,
+		NotificationAPIHandler: notificationAPIHandler,
+		CommentAPIHandler:      commentAPIHandler,
+		EventAPIHandler:        eventAPIHandler,
+		CollabAPIHandler:       collabAPIHandler,
+		WebhookAPIHandler:      webhookAPIHandler,
+		GraphQLAPIHandler:      graphQLAPIHandler,
+		BoardAPIHandler:        boardAPIHandler,
+		TaskCSVAPIHandler:      taskCSVAPIHandler,
+		MarkdownAPIHandler:     markdownAPIHandler,
+		CalendarAPIHandler:     calendarAPIHandler,
 	}
 
 	MuxRoute(mux, "POST", "/api/v1/users/login", middleware.Post(http.HandlerFunc(apiHandler.UserAPIHandler.Login)))
@@ -96,16 +191,100 @@ func RunServer(db *gorm.DB, mux *http.ServeMux) *http.ServeMux {
 	MuxRoute(mux, "GET", "/api/v1/users/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.UserAPIHandler.GetUserById))), "?user_id=")
 	MuxRoute(mux, "DELETE", "/api/v1/users/delete", middleware.Delete(http.HandlerFunc(apiHandler.UserAPIHandler.Delete)), "?user_id=")
 
-	MuxRoute(mux, "GET", "/api/v1/tasks/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.GetTask))), "?task_id=")
-	MuxRoute(mux, "POST", "/api/v1/tasks/create", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.CreateNewTask))))
+	MuxRoute(mux, "GET", "/api/v1/tasks/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.GetTask))), "?task_id=&page=&per_page=&archived=&category_id=&q=&created_from=&created_to=&updated_from=&updated_to=&sort=&order=&field_id=&field_value=&sort_field_id=")
+	MuxRoute(mux, "GET", "/api/v1/tasks/search", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.SearchTasks))), "?q=&category_id=&limit=")
+	MuxRoute(mux, "GET", "/api/v1/tasks/export", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.TaskCSVAPIHandler.ExportTasksCSV))), "?columns=&archived=&category_id=&q=&created_from=&created_to=&updated_from=&updated_to=&sort=&order=")
+	MuxRoute(mux, "POST", "/api/v1/tasks/import", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.TaskCSVAPIHandler.ImportTasksCSV))))
+	MuxRoute(mux, "POST", "/api/v1/tasks/create", middleware.Post(middleware.Auth(idempotent(http.HandlerFunc(apiHandler.TaskAPIHandler.CreateNewTask)))))
 	MuxRoute(mux, "PUT", "/api/v1/tasks/update", middleware.Put(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.UpdateTask))), "?task_id=")
 	MuxRoute(mux, "PUT", "/api/v1/tasks/update/category", middleware.Put(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.UpdateTaskCategory))), "?task_id=")
+	MuxRoute(mux, "POST", "/api/v1/tasks/bulk", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.BulkTasks))))
 	MuxRoute(mux, "DELETE", "/api/v1/tasks/delete", middleware.Delete(middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.DeleteTask))), "?task_id=")
 
 	MuxRoute(mux, "GET", "/api/v1/categories/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.CategoryAPIHandler.GetCategory))))
 	MuxRoute(mux, "GET", "/api/v1/categories/dashboard", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.CategoryAPIHandler.GetCategoryWithTasks))))
-	MuxRoute(mux, "POST", "/api/v1/categories/create", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.CategoryAPIHandler.CreateNewCategory))))
+	MuxRoute(mux, "POST", "/api/v1/categories/create", middleware.Post(middleware.Auth(idempotent(http.HandlerFunc(apiHandler.CategoryAPIHandler.CreateNewCategory)))))
 	MuxRoute(mux, "DELETE", "/api/v1/categories/delete", middleware.Delete(middleware.Auth(http.HandlerFunc(apiHandler.CategoryAPIHandler.DeleteCategory))), "?category_id=")
+	MuxRoute(mux, "GET", "/api/v1/boards/events", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.EventAPIHandler.StreamBoardEvents))))
+	MuxRoute(mux, "GET", "/api/v1/boards/collab", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.CollabAPIHandler.Connect))))
+	MuxRoute(mux, "GET", "/api/v1/boards/export", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.BoardAPIHandler.ExportBoard))))
+	MuxRoute(mux, "GET", "/api/v1/boards/markdown", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.MarkdownAPIHandler.ExportMarkdown))), "?category_id=&completed=&from=&to=&group_by=")
+	MuxRoute(mux, "POST", "/api/v1/boards/import", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.BoardAPIHandler.ImportBoard))))
+	MuxRoute(mux, "POST", "/api/v1/boards/import/trello", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.BoardAPIHandler.ImportTrello))), "?dry_run=")
+
+	MuxRoute(mux, "POST", "/api/v1/tasks/timer/start", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.TimeEntryAPIHandler.StartTimer))), "?task_id=")
+	MuxRoute(mux, "POST", "/api/v1/tasks/timer/stop", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.TimeEntryAPIHandler.StopTimer))))
+	MuxRoute(mux, "POST", "/api/v1/time-entries/create", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.TimeEntryAPIHandler.CreateTimeEntry))))
+	MuxRoute(mux, "GET", "/api/v1/time-entries/report", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.TimeEntryAPIHandler.GetTimeReport))), "?task_id=&user_id=&from=&to=")
+
+	MuxRoute(mux, "GET", "/api/v1/custom-fields/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.CustomFieldAPIHandler.GetCustomFields))))
+	MuxRoute(mux, "POST", "/api/v1/custom-fields/create", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.CustomFieldAPIHandler.CreateNewCustomField))))
+	MuxRoute(mux, "DELETE", "/api/v1/custom-fields/delete", middleware.Delete(middleware.Auth(http.HandlerFunc(apiHandler.CustomFieldAPIHandler.DeleteCustomField))), "?field_id=")
+
+	MuxRoute(mux, "POST", "/api/v1/tasks/watch", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.WatchTask))), "?task_id=")
+	MuxRoute(mux, "DELETE", "/api/v1/tasks/unwatch", middleware.Delete(middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.UnwatchTask))), "?task_id=")
+	MuxRoute(mux, "GET", "/api/v1/notifications/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.GetNotifications))), "?unread=")
+	MuxRoute(mux, "GET", "/api/v1/notifications/count", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.CountUnread))))
+	MuxRoute(mux, "PUT", "/api/v1/notifications/read", middleware.Put(middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.MarkRead))))
+
+	MuxRoute(mux, "GET", "/api/v1/comments/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.CommentAPIHandler.GetComments))), "?task_id=")
+	MuxRoute(mux, "POST", "/api/v1/comments/create", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.CommentAPIHandler.CreateNewComment))), "?task_id=")
+
+	MuxRoute(mux, "GET", "/api/v1/webhooks/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.WebhookAPIHandler.GetWebhooks))))
+	MuxRoute(mux, "POST", "/api/v1/webhooks/create", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.WebhookAPIHandler.CreateNewWebhook))))
+	MuxRoute(mux, "DELETE", "/api/v1/webhooks/delete", middleware.Delete(middleware.Auth(http.HandlerFunc(apiHandler.WebhookAPIHandler.DeleteWebhook))), "?webhook_id=")
+	MuxRoute(mux, "GET", "/api/v1/webhooks/deliveries", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.WebhookAPIHandler.GetDeliveries))), "?webhook_id=")
+	MuxRoute(mux, "POST", "/api/v1/webhooks/deliveries/redeliver", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.WebhookAPIHandler.Redeliver))), "?delivery_id=")
+
+	MuxRoute(mux, "GET", "/api/v1/calendar/get", middleware.Get(middleware.Auth(http.HandlerFunc(apiHandler.CalendarAPIHandler.GetCalendarFeed))))
+	MuxRoute(mux, "POST", "/api/v1/calendar/token", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.CalendarAPIHandler.RegenerateCalendarToken))))
+	MuxRoute(mux, "DELETE", "/api/v1/calendar/delete", middleware.Delete(middleware.Auth(http.HandlerFunc(apiHandler.CalendarAPIHandler.DeleteCalendarFeed))))
+	MuxRoute(mux, "GET", "/api/v1/calendar/feed.ics", middleware.Get(http.HandlerFunc(apiHandler.CalendarAPIHandler.CalendarFeed)), "?token=&board_id=&category_id=&label=&type=")
+
+	MuxRoute(mux, "POST", "/api/v1/graphql", middleware.Post(middleware.Auth(http.HandlerFunc(apiHandler.GraphQLAPIHandler.Query))))
+
+	v2 := NewRouter()
+	v2.Handle("GET", "/api/v2/tasks", middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.GetTask)), "?page=&per_page=&archived=&category_id=&q=&created_from=&created_to=&updated_from=&updated_to=&sort=&order=&field_id=&field_value=&sort_field_id=")
+	v2.Handle("POST", "/api/v2/tasks", middleware.Auth(idempotent(http.HandlerFunc(apiHandler.TaskAPIHandler.CreateNewTask))))
+	v2.Handle("POST", "/api/v2/tasks/bulk", middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.BulkTasks)))
+	v2.Handle("GET", "/api/v2/tasks/search", middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.SearchTasks)), "?q=&category_id=&limit=")
+	v2.Handle("GET", "/api/v2/tasks/export", middleware.Auth(http.HandlerFunc(apiHandler.TaskCSVAPIHandler.ExportTasksCSV)), "?columns=&archived=&category_id=&q=&created_from=&created_to=&updated_from=&updated_to=&sort=&order=")
+	v2.Handle("POST", "/api/v2/tasks/import", middleware.Auth(http.HandlerFunc(apiHandler.TaskCSVAPIHandler.ImportTasksCSV)))
+	v2.Handle("GET", "/api/v2/tasks/{task_id}", middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.GetTask)))
+	v2.Handle("PATCH", "/api/v2/tasks/{task_id}", middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.PatchTask)))
+	v2.Handle("DELETE", "/api/v2/tasks/{task_id}", middleware.Auth(http.HandlerFunc(apiHandler.TaskAPIHandler.DeleteTask)))
+	v2.Handle("GET", "/api/v2/tasks/{task_id}/comments", middleware.Auth(http.HandlerFunc(apiHandler.CommentAPIHandler.GetComments)))
+	v2.Handle("POST", "/api/v2/tasks/{task_id}/comments", middleware.Auth(http.HandlerFunc(apiHandler.CommentAPIHandler.CreateNewComment)))
+	v2.Handle("POST", "/api/v2/tasks/{task_id}/watchers", middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.WatchTask)))
+	v2.Handle("DELETE", "/api/v2/tasks/{task_id}/watchers", middleware.Auth(http.HandlerFunc(apiHandler.NotificationAPIHandler.UnwatchTask)))
+	v2.Handle("POST", "/api/v2/tasks/{task_id}/timer/start", middleware.Auth(http.HandlerFunc(apiHandler.TimeEntryAPIHandler.StartTimer)))
+
+	v2.Handle("GET", "/api/v2/boards/{board_id}", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.CategoryAPIHandler.GetCategoryWithTasks))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/events", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.EventAPIHandler.StreamBoardEvents))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/collab", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.CollabAPIHandler.Connect))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/export", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.BoardAPIHandler.ExportBoard))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/markdown", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.MarkdownAPIHandler.ExportMarkdown))), "?category_id=&completed=&from=&to=&group_by=")
+	v2.Handle("POST", "/api/v2/boards/{board_id}/import", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.BoardAPIHandler.ImportBoard))))
+	v2.Handle("POST", "/api/v2/boards/{board_id}/import/trello", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.BoardAPIHandler.ImportTrello))), "?dry_run=")
+	v2.Handle("GET", "/api/v2/boards/{board_id}/categories", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.CategoryAPIHandler.GetCategory))))
+	v2.Handle("POST", "/api/v2/boards/{board_id}/categories", middleware.Auth(middleware.BoardOwner(idempotent(http.HandlerFunc(apiHandler.CategoryAPIHandler.CreateNewCategory)))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/categories/{category_id}", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.CategoryAPIHandler.GetCategoryByID))))
+	v2.Handle("PATCH", "/api/v2/boards/{board_id}/categories/{category_id}", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.CategoryAPIHandler.PatchCategory))))
+	v2.Handle("DELETE", "/api/v2/boards/{board_id}/categories/{category_id}", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.CategoryAPIHandler.DeleteCategory))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/webhooks", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.WebhookAPIHandler.GetWebhooks))))
+	v2.Handle("POST", "/api/v2/boards/{board_id}/webhooks", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.WebhookAPIHandler.CreateNewWebhook))))
+	v2.Handle("DELETE", "/api/v2/boards/{board_id}/webhooks/{webhook_id}", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.WebhookAPIHandler.DeleteWebhook))))
+	v2.Handle("GET", "/api/v2/boards/{board_id}/webhooks/{webhook_id}/deliveries", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.WebhookAPIHandler.GetDeliveries))))
+	v2.Handle("POST", "/api/v2/boards/{board_id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", middleware.Auth(middleware.BoardOwner(http.HandlerFunc(apiHandler.WebhookAPIHandler.Redeliver))))
+
+	mux.Handle("/api/v2/", v2)
+
+	kanbanv1.RegisterUserServiceServer(grpcServer, api.NewUserGRPC(userService))
+	kanbanv1.RegisterCategoryServiceServer(grpcServer, api.NewCategoryGRPC(categoryService, eventBus))
+	kanbanv1.RegisterTaskServiceServer(grpcServer, api.NewTaskGRPC(taskService, categoryService))
+
+	MuxRoute(mux, "GET", "/api/openapi.json", middleware.Get(http.HandlerFunc(OpenAPIHandler)))
+	MuxRoute(mux, "GET", "/api/docs", middleware.Get(http.HandlerFunc(APIDocsHandler)))
 
 	return mux
 }
@@ -114,14 +293,18 @@ func RunClient(mux *http.ServeMux, embed embed.FS) *http.ServeMux {
 	userClient := client.NewUserClient()
 	categoryClient := client.NewCategoryClient()
 	taskClient := client.NewTaskClient()
+	notificationClient := client.NewNotificationClient()
+	commentClient := client.NewCommentClient()
 
 	authWeb := web.NewAuthWeb(userClient, embed)
-	dashboardWeb := web.NewDashboardWeb(categoryClient, userClient, embed)
-	modifyWeb := web.NewModifyWeb(taskClient, categoryClient, embed)
+	dashboardWeb := web.NewDashboardWeb(categoryClient, userClient, notificationClient, embed)
+	modifyWeb := web.NewModifyWeb(taskClient, categoryClient, commentClient, embed)
 	homeWeb := web.NewHomeWeb(embed)
+	notificationWeb := web.NewNotificationWeb(notificationClient, embed)
+	searchWeb := web.NewSearchWeb(taskClient, categoryClient, embed)
 
 	client := ClientHandler{
-		authWeb, dashboardWeb, modifyWeb, homeWeb,
+		authWeb, dashboardWeb, modifyWeb, homeWeb, notificationWeb, searchWeb,
 	}
 
 	mux.HandleFunc("/login", client.AuthWeb.Login)
@@ -143,9 +326,16 @@ func RunClient(mux *http.ServeMux, embed embed.FS) *http.ServeMux {
 	mux.Handle("/task/update", middleware.Auth(http.HandlerFunc(client.ModifyWeb.UpdateTask)))
 	mux.Handle("/task/update/process", middleware.Auth(http.HandlerFunc(client.ModifyWeb.UpdateTaskProcess)))
 
+	mux.Handle("/task/comment", middleware.Auth(http.HandlerFunc(client.ModifyWeb.AddCommentProcess)))
+
 	mux.Handle("/task/delete", middleware.Auth(http.HandlerFunc(client.ModifyWeb.DeleteTask)))
 	mux.Handle("/category/delete", middleware.Auth(http.HandlerFunc(client.ModifyWeb.DeleteCategory)))
 
+	mux.Handle("/notifications", middleware.Auth(http.HandlerFunc(client.NotificationWeb.Notifications)))
+	mux.Handle("/notifications/read", middleware.Auth(http.HandlerFunc(client.NotificationWeb.MarkAllRead)))
+
+	mux.Handle("/search", middleware.Auth(http.HandlerFunc(client.SearchWeb.Search)))
+
 	mux.HandleFunc("/", client.HomeWeb.Index)
 
 	return mux
@@ -158,5 +348,6 @@ func MuxRoute(mux *http.ServeMux, method string, path string, handler http.Handl
 		fmt.Printf("[%s]: %s \n", method, path)
 	}
 
+	registerRoute(method, path, opt)
 	mux.Handle(path, handler)
 }
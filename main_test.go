@@ -1,21 +1,42 @@
 package main_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	main "github.com/snykk/kanban-app"
+	"github.com/snykk/kanban-app/config"
 	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/middleware"
+	kanbanv1 "github.com/snykk/kanban-app/proto/kanban/v1"
 	"github.com/snykk/kanban-app/repository"
+	"github.com/snykk/kanban-app/service"
 
 	_ "github.com/jackc/pgx/v4/stdlib"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -48,11 +69,15 @@ func SetCookie(mux *http.ServeMux) *http.Cookie {
 
 var _ = Describe("TestAPIHandler", Ordered, func() {
 	var apiServer *http.ServeMux
+	var grpcServer *grpc.Server
 	var db *gorm.DB
 	var userTest int
 	var categoryIdTest int
 	var categoryIdForTaskTest int
 	var taskIdTest int
+	var customFieldIdTest int
+	var watcherTest int
+	var watcherCookie *http.Cookie
 
 	BeforeAll(func() {
 		conn, err := gorm.Open(postgres.New(postgres.Config{
@@ -65,20 +90,99 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 
 		db = conn
 
+		db.Exec("DROP TABLE IF EXISTS calendar_feeds CASCADE")
+		db.Exec("DROP TABLE IF EXISTS webhook_deliveries CASCADE")
+		db.Exec("DROP TABLE IF EXISTS webhooks CASCADE")
+		db.Exec("DROP TABLE IF EXISTS idempotency_keys CASCADE")
+		db.Exec("DROP TABLE IF EXISTS mentions CASCADE")
+		db.Exec("DROP TABLE IF EXISTS comments CASCADE")
+		db.Exec("DROP TABLE IF EXISTS notifications CASCADE")
+		db.Exec("DROP TABLE IF EXISTS watchers CASCADE")
+		db.Exec("DROP TABLE IF EXISTS custom_field_values CASCADE")
+		db.Exec("DROP TABLE IF EXISTS custom_fields CASCADE")
+		db.Exec("DROP TABLE IF EXISTS time_entries CASCADE")
 		db.Exec("DROP TABLE IF EXISTS tasks CASCADE")
 		db.Exec("DROP TABLE IF EXISTS categories CASCADE")
 		db.Exec("DROP TABLE IF EXISTS users CASCADE")
 
-		db.AutoMigrate(entity.User{}, entity.Category{}, entity.Task{})
+		db.AutoMigrate(entity.User{}, entity.Category{}, entity.Task{}, entity.TimeEntry{}, entity.CustomField{}, entity.CustomFieldValue{}, entity.Watcher{}, entity.Notification{}, entity.Comment{}, entity.Mention{}, entity.IdempotencyKey{}, entity.Webhook{}, entity.WebhookDelivery{}, entity.CalendarFeed{})
+		repository.CreateSearchIndexes(db)
+
+		// the webhook receivers of the tests listen on loopback
+		config.AppConfig.WebhookAllowPrivateNetworks = true
 
 		apiServer = http.NewServeMux()
-		apiServer = main.RunServer(db, apiServer)
+		grpcServer = grpc.NewServer(
+			grpc.UnaryInterceptor(middleware.GRPCAuth),
+			grpc.StreamInterceptor(middleware.GRPCStreamAuth),
+		)
+		apiServer = main.RunServer(db, apiServer, grpcServer)
 	})
 
 	AfterAll(func() {
 		ctx := context.Background()
 
-		err := db.WithContext(ctx).Exec("DELETE FROM tasks WHERE user_id = ?", userTest).Error
+		err := db.WithContext(ctx).Exec("DELETE FROM calendar_feeds WHERE user_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM webhook_deliveries WHERE webhook_id IN (SELECT id FROM webhooks WHERE user_id = ?)", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM webhooks WHERE user_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM idempotency_keys WHERE user_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM mentions WHERE actor_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM comments WHERE user_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM notifications WHERE user_id IN (?, ?)", userTest, watcherTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM watchers WHERE user_id IN (?, ?)", userTest, watcherTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM users WHERE id = ?", watcherTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM custom_field_values WHERE task_id IN (SELECT id FROM tasks WHERE user_id = ?)", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM custom_fields WHERE user_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM time_entries WHERE user_id = ?", userTest).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM tasks WHERE user_id = ?", userTest).Error
 		if err != nil {
 			panic(err)
 		}
@@ -96,7 +200,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 
 	Describe("/users/register", func() {
 		When("send empty register request data", func() {
-			It("should return a bad request", func() {
+			It("should return the missing fields", func() {
 				reqRegister := entity.UserRegister{}
 				reqBody, _ := json.Marshal(reqRegister)
 
@@ -106,11 +210,69 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 
 				apiServer.ServeHTTP(w, r)
 
-				errResp := entity.ErrorResponse{}
-				err := json.Unmarshal(w.Body.Bytes(), &errResp)
+				problem := entity.Problem{}
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
-				Expect(errResp.Error).To(Equal("register data is empty"))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(w.Result().Header.Get("Content-Type")).To(Equal("application/problem+json"))
+				Expect(problem.Code).To(Equal("validation_failed"))
+				Expect(problem.Error).To(Equal("invalid register request"))
+				Expect(problem.Errors).To(Equal([]entity.FieldError{
+					{Field: "fullname", Message: "is required"},
+					{Field: "email", Message: "is required"},
+					{Field: "password", Message: "is required"},
+				}))
+			})
+		})
+
+		When("send register request data with an invalid email and a short password", func() {
+			It("should return both field errors", func() {
+				reqRegister := entity.UserRegister{
+					Fullname: "test",
+					Email:    "not-an-email",
+					Password: "abc",
+				}
+				reqBody, _ := json.Marshal(reqRegister)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/users/register", bytes.NewReader(reqBody))
+				r.Header.Set("Content-Type", "application/json")
+
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(problem.Errors).To(Equal([]entity.FieldError{
+					{Field: "email", Message: "must be a valid email address"},
+					{Field: "password", Message: "must be at least 6 characters"},
+				}))
+			})
+		})
+
+		When("send register request data with a password over 72 bytes", func() {
+			It("should count the bytes bcrypt reads", func() {
+				reqRegister := entity.UserRegister{
+					Fullname: "test",
+					Email:    "bytes@mail.com",
+					Password: strings.Repeat("é", 40),
+				}
+				reqBody, _ := json.Marshal(reqRegister)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/users/register", bytes.NewReader(reqBody))
+				r.Header.Set("Content-Type", "application/json")
+
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(problem.Errors).To(Equal([]entity.FieldError{
+					{Field: "password", Message: "must be at most 72 bytes"},
+				}))
 			})
 		})
 
@@ -141,7 +303,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 		})
 
 		When("send register twice with same data by POST method", func() {
-			It("should return a bad request", func() {
+			It("should return a conflict", func() {
 				reqRegister := entity.UserRegister{
 					Fullname: "test",
 					Email:    "test@mail.com",
@@ -156,11 +318,12 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 
 				apiServer.ServeHTTP(w, r)
 
-				errResp := entity.ErrorResponse{}
-				err := json.Unmarshal(w.Body.Bytes(), &errResp)
+				problem := entity.Problem{}
+				err := json.Unmarshal(w.Body.Bytes(), &problem)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusInternalServerError))
-				Expect(errResp.Error).NotTo(BeEmpty())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusConflict))
+				Expect(problem.Code).To(Equal("email_already_exists"))
+				Expect(problem.Detail).To(Equal("email already exists"))
 			})
 		})
 	})
@@ -183,8 +346,32 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				errResp := entity.ErrorResponse{}
 				err := json.Unmarshal(w.Body.Bytes(), &errResp)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
-				Expect(errResp.Error).To(Equal("email or password is empty"))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(errResp.Error).To(Equal("invalid login request"))
+			})
+		})
+
+		When("send a wrong password or an unknown email with POST method", func() {
+			It("should return the same unauthorized problem", func() {
+				for _, loginData := range []entity.UserLogin{
+					{Email: "test@mail.com", Password: "wrong-password"},
+					{Email: "nobody@mail.com", Password: "testing123"},
+				} {
+					body, _ := json.Marshal(loginData)
+					w := httptest.NewRecorder()
+					r := httptest.NewRequest("POST", "/api/v1/users/login", bytes.NewReader(body))
+					r.Header.Set("Content-Type", "application/json")
+
+					apiServer.ServeHTTP(w, r)
+
+					problem := entity.Problem{}
+					err := json.Unmarshal(w.Body.Bytes(), &problem)
+					Expect(err).To(BeNil())
+					Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+					Expect(problem.Status).To(Equal(http.StatusUnauthorized))
+					Expect(problem.Code).To(Equal("wrong_credentials"))
+					Expect(problem.Detail).To(Equal("wrong email or password"))
+				}
 			})
 		})
 
@@ -339,7 +526,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 		})
 
 		When("hit endpoint with POST method without required data", func() {
-			It("should return a bad request", func() {
+			It("should return a validation error", func() {
 				w := httptest.NewRecorder()
 				r := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte("{}")))
 				r.Header.Set("Content-Type", "application/json")
@@ -350,7 +537,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				var resp = entity.ErrorResponse{}
 				err := json.NewDecoder(w.Body).Decode(&resp)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
 				Expect(resp.Error).To(Equal("invalid category request"))
 			})
 		})
@@ -416,7 +603,17 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 		})
 
 		When("hit endpoint with DELETE method", func() {
-			It("should return a success", func() {
+			It("should delete the category with its tasks and their rows", func() {
+				task := entity.Task{Title: "Deleted with its column", CategoryID: categoryIdTest, UserID: userTest}
+				err := db.Create(&task).Error
+				Expect(err).To(BeNil())
+
+				ended := time.Now()
+				err = db.Create(&entity.TimeEntry{TaskID: task.ID, UserID: userTest, StartedAt: ended.Add(-time.Hour), EndedAt: &ended, Duration: 3600}).Error
+				Expect(err).To(BeNil())
+				err = db.Create(&entity.Notification{UserID: userTest, ActorID: userTest, TaskID: task.ID, Type: entity.NotificationEdited, Message: "edited"}).Error
+				Expect(err).To(BeNil())
+
 				w := httptest.NewRecorder()
 				r := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/categories/delete?category_id=%v", categoryIdTest), nil)
 				r.Header.Set("Content-Type", "application/json")
@@ -425,10 +622,20 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				apiServer.ServeHTTP(w, r)
 
 				var resp = map[string]interface{}{}
-				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				err = json.Unmarshal(w.Body.Bytes(), &resp)
 				Expect(err).To(BeNil())
 				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
 				Expect(resp["message"]).To(Equal("success delete category"))
+
+				var count int64
+				err = db.Model(&entity.Task{}).Where("id = ?", task.ID).Count(&count).Error
+				Expect(err).To(BeNil())
+				Expect(count).To(BeZero())
+				for _, model := range []interface{}{&entity.TimeEntry{}, &entity.Notification{}} {
+					err = db.Model(model).Where("task_id = ?", task.ID).Count(&count).Error
+					Expect(err).To(BeNil())
+					Expect(count).To(BeZero())
+				}
 			})
 		})
 	})
@@ -472,7 +679,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 		})
 
 		When("hit endpoint with POST method without required data", func() {
-			It("should return error bad request", func() {
+			It("should return every missing field", func() {
 				w := httptest.NewRecorder()
 				r := httptest.NewRequest("POST", "/api/v1/tasks/create", bytes.NewReader([]byte("{}")))
 				r.Header.Set("Content-Type", "application/json")
@@ -480,11 +687,43 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 
 				apiServer.ServeHTTP(w, r)
 
-				var resp = entity.ErrorResponse{}
+				var resp = entity.Problem{}
 				err := json.NewDecoder(w.Body).Decode(&resp)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
 				Expect(resp.Error).To(Equal("invalid task request"))
+				Expect(resp.Errors).To(Equal([]entity.FieldError{
+					{Field: "title", Message: "is required"},
+					{Field: "description", Message: "is required"},
+					{Field: "category_id", Message: "is required"},
+				}))
+			})
+		})
+
+		When("hit endpoint with POST method and negative estimate", func() {
+			It("should return a validation error", func() {
+				taskData := entity.TaskRequest{
+					CategoryID:  categoryIdForTaskTest,
+					Title:       "Testing",
+					Description: "Testing",
+					Estimate:    -1,
+				}
+
+				body, _ := json.Marshal(taskData)
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/create", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(resp.Errors).To(Equal([]entity.FieldError{
+					{Field: "estimate", Message: "must be a number from 0 to 1000"},
+				}))
 			})
 		})
 
@@ -494,6 +733,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 					CategoryID:  categoryIdForTaskTest,
 					Title:       "Testing",
 					Description: "Testing",
+					Estimate:    3,
 				}
 
 				body, _ := json.Marshal(taskData)
@@ -540,14 +780,51 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				r.AddCookie(SetCookie(apiServer))
 				apiServer.ServeHTTP(w, r)
 
-				var resp = []entity.Task{}
+				var resp = entity.TaskPage{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Total).To(Equal(int64(1)))
+				Expect(resp.Page).To(Equal(1))
+				Expect(len(resp.Data)).To(Equal(1))
+				Expect(resp.Data[0].Title).To(Equal("Testing"))
+				Expect(resp.Data[0].Description).To(Equal("Testing"))
+				Expect(resp.Data[0].CategoryID).To(Equal(categoryIdForTaskTest))
+			})
+		})
+
+		When("hit endpoint with GET method and paginate with a text filter", func() {
+			It("should return the matching page with the total count", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/tasks/get?q=test&page=2&per_page=1&sort=created_at&order=desc", nil)
+
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.TaskPage{}
 				err := json.NewDecoder(w.Body).Decode(&resp)
 				Expect(err).To(BeNil())
 				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
-				Expect(len(resp)).To(Equal(1))
-				Expect(resp[0].Title).To(Equal("Testing"))
-				Expect(resp[0].Description).To(Equal("Testing"))
-				Expect(resp[0].CategoryID).To(Equal(categoryIdForTaskTest))
+				Expect(resp.Total).To(Equal(int64(1)))
+				Expect(resp.PerPage).To(Equal(1))
+				Expect(resp.TotalPages).To(Equal(1))
+				Expect(len(resp.Data)).To(Equal(0))
+			})
+		})
+
+		When("hit endpoint with GET method and an unknown sort key", func() {
+			It("should return a bad request", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/tasks/get?sort=password", nil)
+
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				errResp := entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(errResp.Error).To(Equal("invalid sort key"))
 			})
 		})
 
@@ -566,6 +843,7 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				Expect(resp.Title).To(Equal("Testing"))
 				Expect(resp.Description).To(Equal("Testing"))
 				Expect(resp.CategoryID).To(Equal(categoryIdForTaskTest))
+				Expect(resp.Estimate).To(Equal(3.0))
 			})
 		})
 	})
@@ -629,46 +907,97 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				Expect(resp["message"]).To(Equal("success update task"))
 			})
 		})
-	})
 
-	Describe("/tasks/delete", func() {
-		When("hit endpoint without user login", func() {
-			It("should return an error unauthorized", func() {
+		When("clear the estimate with PUT method", func() {
+			It("should store an estimate of 0", func() {
+				for _, estimate := range []float64{5, 0} {
+					body, _ := json.Marshal(entity.TaskRequest{Title: "Testing Updated", Description: "Testing Updated", Estimate: estimate})
+					w := httptest.NewRecorder()
+					r := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/tasks/update?task_id=%v", taskIdTest), bytes.NewReader(body))
+					r.Header.Set("Content-Type", "application/json")
+					r.AddCookie(SetCookie(apiServer))
+					apiServer.ServeHTTP(w, r)
+					Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+					w = httptest.NewRecorder()
+					r = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/get?task_id=%v", taskIdTest), nil)
+					r.AddCookie(SetCookie(apiServer))
+					apiServer.ServeHTTP(w, r)
+
+					var resp = entity.Task{}
+					err := json.NewDecoder(w.Body).Decode(&resp)
+					Expect(err).To(BeNil())
+					Expect(resp.Estimate).To(Equal(estimate))
+				}
+			})
+		})
+
+		When("move the task to another category", func() {
+			It("should return the new ETag", func() {
+				body, _ := json.Marshal(entity.TaskCategoryRequest{ID: taskIdTest, CategoryID: categoryIdForTaskTest})
 				w := httptest.NewRecorder()
-				r := httptest.NewRequest("DELETE", "/api/v1/tasks/delete", nil)
+				r := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/tasks/update/category?task_id=%v", taskIdTest), bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				etag := w.Result().Header.Get("ETag")
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(etag).ToNot(BeEmpty())
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().Header.Get("ETag")).To(Equal(etag))
+			})
+		})
+
+		When("move the task to a category that does not exist", func() {
+			It("should return category_not_found", func() {
+				body, _ := json.Marshal(entity.TaskCategoryRequest{ID: taskIdTest, CategoryID: 2147483647})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/tasks/update/category?task_id=%v", taskIdTest), bytes.NewReader(body))
 				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
 
 				apiServer.ServeHTTP(w, r)
 
-				var errResp = entity.ErrorResponse{}
-				err := json.NewDecoder(w.Body).Decode(&errResp)
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
-				Expect(errResp.Error).To(Equal("error unauthorized user id"))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+				Expect(problem.Code).To(Equal("category_not_found"))
 			})
 		})
+	})
 
-		When("hit endpoint with GET method", func() {
-			It("should return a success", func() {
+	Describe("/custom-fields", func() {
+		When("create a field with an unknown type", func() {
+			It("should return a bad request", func() {
+				body, _ := json.Marshal(entity.CustomFieldRequest{Name: "Customer", Type: "color"})
 				w := httptest.NewRecorder()
-				r := httptest.NewRequest("GET", "/api/v1/tasks/delete", bytes.NewReader([]byte("{}")))
+				r := httptest.NewRequest("POST", "/api/v1/custom-fields/create", bytes.NewReader(body))
 				r.Header.Set("Content-Type", "application/json")
 				r.AddCookie(SetCookie(apiServer))
 
 				apiServer.ServeHTTP(w, r)
 
-				var resp = entity.ErrorResponse{}
-				err := json.Unmarshal(w.Body.Bytes(), &resp)
-				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusMethodNotAllowed))
-				Expect(resp.Error).To(Equal("method is not allowed!"))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
 			})
 		})
 
-		When("hit endpoint with DELETE method", func() {
+		When("create a select field with POST method", func() {
 			It("should return a success", func() {
+				body, _ := json.Marshal(entity.CustomFieldRequest{
+					Name:    "Environment",
+					Type:    entity.CustomFieldSelect,
+					Options: []string{"staging", "production"},
+				})
 				w := httptest.NewRecorder()
-				r := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/tasks/delete?task_id=%v", taskIdTest), nil)
+				r := httptest.NewRequest("POST", "/api/v1/custom-fields/create", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
 				r.AddCookie(SetCookie(apiServer))
 
 				apiServer.ServeHTTP(w, r)
@@ -676,59 +1005,1755 @@ var _ = Describe("TestAPIHandler", Ordered, func() {
 				var resp = map[string]interface{}{}
 				err := json.Unmarshal(w.Body.Bytes(), &resp)
 				Expect(err).To(BeNil())
-				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
-				Expect(int(resp["task_id"].(float64))).To(Equal(taskIdTest))
-				Expect(resp["message"]).To(Equal("success delete task"))
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(resp["message"]).To(Equal("success create new custom field"))
+
+				customFieldIdTest = int(resp["field_id"].(float64))
 			})
 		})
-	})
-})
 
-var _ = Describe("TestWebHandler", Ordered, func() {
-	var clientHandler *http.ServeMux
-	var db *gorm.DB
-	var userClientID int
+		When("set a value outside the select options on a task", func() {
+			It("should return a bad request", func() {
+				body := []byte(fmt.Sprintf(`{"title": "Testing Updated", "description": "Testing Updated", "custom_fields": [{"field_id": %d, "value": "qa"}]}`, customFieldIdTest))
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/tasks/update?task_id=%v", taskIdTest), bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
 
-	BeforeAll(func() {
-		err := repository.ConnectDB()
-		if err != nil {
-			panic(err)
-		}
+				apiServer.ServeHTTP(w, r)
 
-		db = repository.GetDBConnection()
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
 
-		clientHandler = http.NewServeMux()
+		When("set a valid value and filter the task listing by it", func() {
+			It("should return the task with the value", func() {
+				body := []byte(fmt.Sprintf(`{"title": "Testing Updated", "description": "Testing Updated", "custom_fields": [{"field_id": %d, "value": "production"}]}`, customFieldIdTest))
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/tasks/update?task_id=%v", taskIdTest), bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
 
-		clientHandler = main.RunServer(db, clientHandler)
-		clientHandler = main.RunClient(clientHandler, main.Resources)
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
 
-		register := entity.UserRegister{
-			Fullname: "testing client",
-			Email:    "test@mail.com",
-			Password: "testing123",
-		}
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/get?field_id=%v&field_value=production", customFieldIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
 
-		byte, _ := json.Marshal(register)
+				apiServer.ServeHTTP(w, r)
 
-		w := httptest.NewRecorder()
-		r := httptest.NewRequest("POST", "/api/v1/users/register", bytes.NewReader(byte))
-		r.Header.Set("Content-Type", "application/json")
+				var resp = entity.TaskPage{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(len(resp.Data)).To(Equal(1))
+				Expect(resp.Data[0].CustomFields[0].Name).To(Equal("Environment"))
+				Expect(resp.Data[0].CustomFields[0].Value).To(Equal("production"))
+			})
+		})
+	})
 
-		clientHandler.ServeHTTP(w, r)
+	Describe("/notifications", func() {
+		When("another user watches a task they do not own", func() {
+			It("should return not found and not notify them of edits", func() {
+				register, _ := json.Marshal(entity.UserRegister{
+					Fullname: "watcher",
+					Email:    "watcher@mail.com",
+					Password: "testing123",
+				})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/users/register", bytes.NewReader(register))
+				r.Header.Set("Content-Type", "application/json")
+				apiServer.ServeHTTP(w, r)
 
-		var resp = map[string]interface{}{}
-		err = json.Unmarshal(w.Body.Bytes(), &resp)
-		if err != nil {
-			panic(err)
-		}
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				watcherTest = int(resp["user_id"].(float64))
+				watcherCookie = &http.Cookie{Name: "user_id", Value: fmt.Sprintf("%d", watcherTest)}
 
-		userClientID = int(resp["user_id"].(float64))
-	})
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("POST", fmt.Sprintf("/api/v1/tasks/watch?task_id=%v", taskIdTest), nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err = json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+				Expect(problem.Code).To(Equal("task_not_found"))
+
+				body, _ := json.Marshal(entity.TaskRequest{Title: "Testing Watched", Description: "Testing Watched"})
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/tasks/update?task_id=%v", taskIdTest), bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/notifications/get?unread=true", nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+
+				var notifications = []entity.Notification{}
+				err = json.NewDecoder(w.Body).Decode(&notifications)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(len(notifications)).To(Equal(0))
+			})
+		})
+
+		When("the watcher marks notifications read", func() {
+			It("should reset the unread counter", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PUT", "/api/v1/notifications/read", nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/notifications/count", nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp["unread"]).To(Equal(float64(0)))
+			})
+		})
+	})
+
+	Describe("/comments", func() {
+		When("the owner comments and mentions the watcher by email", func() {
+			It("should record the mention and notify the watcher", func() {
+				body, _ := json.Marshal(entity.CommentRequest{Body: "can you check this @watcher@mail.com?"})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/comments/create?task_id=%v", taskIdTest), bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/comments/get?task_id=%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var comments = []entity.Comment{}
+				err := json.NewDecoder(w.Body).Decode(&comments)
+				Expect(err).To(BeNil())
+				Expect(len(comments)).To(Equal(1))
+				Expect(len(comments[0].Mentions)).To(Equal(1))
+				Expect(comments[0].Mentions[0].UserID).To(Equal(watcherTest))
+				Expect(comments[0].Mentions[0].Email).To(Equal("watcher@mail.com"))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/notifications/get?unread=true", nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+
+				var notifications = []entity.Notification{}
+				err = json.NewDecoder(w.Body).Decode(&notifications)
+				Expect(err).To(BeNil())
+
+				var types []string
+				for _, notification := range notifications {
+					types = append(types, notification.Type)
+				}
+				Expect(types).To(ConsistOf(entity.NotificationMentioned))
+			})
+		})
+
+		When("another user reads or comments on the task", func() {
+			It("should return not found", func() {
+				for _, path := range []string{
+					fmt.Sprintf("/api/v1/comments/get?task_id=%v", taskIdTest),
+					fmt.Sprintf("/api/v2/tasks/%v/comments", taskIdTest),
+				} {
+					w := httptest.NewRecorder()
+					r := httptest.NewRequest("GET", path, nil)
+					r.AddCookie(watcherCookie)
+					apiServer.ServeHTTP(w, r)
+
+					problem := entity.Problem{}
+					err := json.NewDecoder(w.Body).Decode(&problem)
+					Expect(err).To(BeNil())
+					Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+					Expect(problem.Code).To(Equal("task_not_found"))
+				}
+
+				body, _ := json.Marshal(entity.CommentRequest{Body: "not my task"})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/comments/create?task_id=%v", taskIdTest), bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("/tasks/search", func() {
+		When("search with an empty query", func() {
+			It("should return a bad request", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/tasks/search?q=", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				errResp := entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(errResp.Error).To(Equal("invalid search query"))
+			})
+		})
+
+		When("search a word from the task title", func() {
+			It("should return the task with a highlighted snippet", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/search?q=watched&category_id=%v", categoryIdForTaskTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.TaskSearchResponse{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(len(resp.Results)).To(Equal(1))
+				Expect(resp.Results[0].TaskID).To(Equal(taskIdTest))
+				Expect(resp.Results[0].Snippet).To(ContainSubstring("<mark>Watched</mark>"))
+			})
+		})
+
+		When("search a word only found in a comment", func() {
+			It("should return the commented task", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/tasks/search?q=check", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.TaskSearchResponse{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(len(resp.Results)).To(Equal(1))
+				Expect(resp.Results[0].TaskID).To(Equal(taskIdTest))
+			})
+		})
+	})
+
+	Describe("/tasks/timer", func() {
+		When("start timer without user login", func() {
+			It("should return an error unauthorized", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/tasks/timer/start?task_id=%v", taskIdTest), nil)
+				r.Header.Set("Content-Type", "application/json")
+
+				apiServer.ServeHTTP(w, r)
+
+				var errResp = entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(errResp.Error).To(Equal("error unauthorized user id"))
+			})
+		})
+
+		When("start timer with POST method", func() {
+			It("should return a success", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/tasks/timer/start?task_id=%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(resp["message"]).To(Equal("success start timer"))
+			})
+		})
+
+		When("start a second timer while one is running", func() {
+			It("should return a conflict", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/tasks/timer/start?task_id=%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var errResp = entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusConflict))
+				Expect(errResp.Error).To(Equal("another timer is already running"))
+			})
+		})
+
+		When("stop timer with POST method", func() {
+			It("should return a success", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/timer/stop", nil)
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp["message"]).To(Equal("success stop timer"))
+			})
+		})
+
+		When("stop timer when none is running", func() {
+			It("should return not found", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/timer/stop", nil)
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("/time-entries", func() {
+		When("create a manual entry that ends before it starts", func() {
+			It("should return a bad request", func() {
+				body := []byte(fmt.Sprintf(`{"task_id": %d, "started_at": "2022-11-02T10:00:00Z", "ended_at": "2022-11-02T09:00:00Z"}`, taskIdTest))
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/time-entries/create", bytes.NewReader(body))
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		When("create a manual entry with POST method", func() {
+			It("should return a success", func() {
+				body := []byte(fmt.Sprintf(`{"task_id": %d, "started_at": "2022-11-02T09:00:00Z", "ended_at": "2022-11-02T10:30:00Z", "note": "review"}`, taskIdTest))
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/time-entries/create", bytes.NewReader(body))
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(resp["duration"]).To(Equal(float64(5400)))
+			})
+		})
+
+		When("get report for a date range", func() {
+			It("should return the tracked time", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/time-entries/report?task_id=%v&from=2022-11-02&to=2022-11-02", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.TimeReport{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.TotalSeconds).To(Equal(int64(5400)))
+				Expect(len(resp.Items)).To(Equal(1))
+				Expect(resp.Items[0].Date).To(Equal("2022-11-02"))
+			})
+		})
+	})
+
+	Describe("/tasks/bulk", func() {
+		When("one of the tasks belongs to another user", func() {
+			It("should apply nothing and report every task", func() {
+				body, _ := json.Marshal(entity.BulkTaskRequest{TaskIDs: []int{taskIdTest, 999999}, Action: entity.BulkActionAddLabel, Label: "cleanup"})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/bulk", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.BulkTaskResponse{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(resp.Applied).To(BeFalse())
+				Expect(resp.Results).To(Equal([]entity.BulkTaskResult{
+					{TaskID: taskIdTest, Status: entity.BulkStatusSkipped},
+					{TaskID: 999999, Status: entity.BulkStatusNotFound, Error: "task not found"},
+				}))
+			})
+		})
+
+		When("every task belongs to the user", func() {
+			It("should apply the action to all of them", func() {
+				body, _ := json.Marshal(entity.BulkTaskRequest{TaskIDs: []int{taskIdTest}, Action: entity.BulkActionAddLabel, Label: "cleanup"})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/bulk", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.BulkTaskResponse{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Applied).To(BeTrue())
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/get?task_id=%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var task = entity.Task{}
+				err = json.NewDecoder(w.Body).Decode(&task)
+				Expect(err).To(BeNil())
+				Expect([]string(task.Labels)).To(Equal([]string{"cleanup"}))
+			})
+		})
+
+		When("the action is unknown", func() {
+			It("should return a bad request", func() {
+				body, _ := json.Marshal(entity.BulkTaskRequest{TaskIDs: []int{taskIdTest}, Action: "explode"})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/bulk", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("/api/v2", func() {
+		When("get a task by its path id", func() {
+			It("should return the task", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.Task{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.ID).To(Equal(taskIdTest))
+			})
+		})
+
+		When("hit a task with an unsupported method", func() {
+			It("should return method not allowed with the allowed methods", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PUT", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusMethodNotAllowed))
+				Expect(w.Result().Header.Get("Allow")).To(Equal("DELETE, GET, PATCH"))
+			})
+		})
+
+		When("merge patch a task", func() {
+			It("should only change the present members and clear the null ones", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PATCH", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), bytes.NewReader([]byte(`{"estimate": 3, "description": null}`)))
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.Task{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Title).To(Equal("Testing Watched"))
+				Expect(resp.Description).To(Equal(""))
+				Expect(resp.Estimate).To(Equal(float64(3)))
+			})
+		})
+
+		When("merge patch a task with invalid members", func() {
+			It("should return the invalid members as field errors", func() {
+				body := `{"title": "` + strings.Repeat("a", 256) + `", "colour": "red", "due_date": "tomorrow"}`
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("PATCH", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), bytes.NewReader([]byte(body)))
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(problem.Code).To(Equal("validation_failed"))
+				Expect(problem.Errors).To(Equal([]entity.FieldError{
+					{Field: "colour", Message: "is not a known field"},
+					{Field: "due_date", Message: "must be a date as YYYY-MM-DD or null"},
+					{Field: "title", Message: "must be at most 255 characters"},
+				}))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("PATCH", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), bytes.NewReader([]byte(`{"title": null}`)))
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem = entity.Problem{}
+				err = json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(problem.Errors).To(Equal([]entity.FieldError{{Field: "title", Message: "must be a non-empty string"}}))
+			})
+		})
+
+		When("patch a task with a stale If-Match", func() {
+			It("should reject the stale write and accept the current one", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				etag := w.Result().Header.Get("ETag")
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(etag).ToNot(BeEmpty())
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("PATCH", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), bytes.NewReader([]byte(`{"estimate": 5}`)))
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+				r.Header.Set("If-Match", etag)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Result().Header.Get("ETag")).ToNot(Equal(etag))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("PATCH", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), bytes.NewReader([]byte(`{"estimate": 8}`)))
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+				r.Header.Set("If-Match", etag)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusPreconditionFailed))
+			})
+		})
+
+		When("patch a task with a weak If-Match", func() {
+			It("should fail the precondition", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				etag := w.Result().Header.Get("ETag")
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("PATCH", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), bytes.NewReader([]byte(`{"estimate": 8}`)))
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+				r.Header.Set("If-Match", "W/"+etag)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusPreconditionFailed))
+			})
+		})
+
+		When("hit an unknown resource", func() {
+			It("should return not found", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v2/tasks/abc", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				errResp := entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+				Expect(errResp.Error).To(Equal("resource not found"))
+			})
+		})
+
+		When("get the categories of another user's board", func() {
+			It("should return not found", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v2/boards/%v/categories", watcherTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		When("get the categories of the own board", func() {
+			It("should return the categories", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", fmt.Sprintf("/api/v2/boards/%v/categories", userTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = []entity.Category{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(len(resp)).ToNot(BeZero())
+			})
+		})
+
+		When("delete a task and a category of another user", func() {
+			It("should return not found and keep them", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v2/tasks/%v", taskIdTest), nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+				Expect(problem.Code).To(Equal("task_not_found"))
+
+				category := entity.Category{}
+				err = db.Where("user_id = ?", userTest).First(&category).Error
+				Expect(err).To(BeNil())
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("DELETE", fmt.Sprintf("/api/v2/boards/%v/categories/%v", watcherTest, category.ID), nil)
+				r.AddCookie(watcherCookie)
+				apiServer.ServeHTTP(w, r)
+
+				problem = entity.Problem{}
+				err = json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+				Expect(problem.Code).To(Equal("category_not_found"))
+
+				var count int64
+				err = db.Model(&entity.Task{}).Where("id = ?", taskIdTest).Count(&count).Error
+				Expect(err).To(BeNil())
+				Expect(count).To(Equal(int64(1)))
+				err = db.Model(&entity.Category{}).Where("id = ?", category.ID).Count(&count).Error
+				Expect(err).To(BeNil())
+				Expect(count).To(Equal(int64(1)))
+			})
+		})
+	})
+
+	Describe("/idempotency", func() {
+		When("retry a create with the same Idempotency-Key", func() {
+			It("should replay the original response", func() {
+				create := func() (*httptest.ResponseRecorder, map[string]interface{}) {
+					w := httptest.NewRecorder()
+					r := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte(`{"type": "Retried"}`)))
+					r.Header.Set("Content-Type", "application/json")
+					r.Header.Set("Idempotency-Key", "retry-create-category")
+					r.AddCookie(SetCookie(apiServer))
+					apiServer.ServeHTTP(w, r)
+
+					var resp = map[string]interface{}{}
+					err := json.NewDecoder(w.Body).Decode(&resp)
+					Expect(err).To(BeNil())
+					return w, resp
+				}
+
+				w, first := create()
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(w.Result().Header.Get("Idempotent-Replayed")).To(BeEmpty())
+
+				w, retried := create()
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(w.Result().Header.Get("Idempotent-Replayed")).To(Equal("true"))
+				Expect(w.Result().Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(retried["category_id"]).To(Equal(first["category_id"]))
+			})
+		})
+
+		When("retry a rejected create with the same Idempotency-Key", func() {
+			It("should replay the problem with its content type", func() {
+				for _, replayed := range []string{"", "true"} {
+					w := httptest.NewRecorder()
+					r := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte(`{"type": ""}`)))
+					r.Header.Set("Content-Type", "application/json")
+					r.Header.Set("Idempotency-Key", "retry-invalid-category")
+					r.AddCookie(SetCookie(apiServer))
+					apiServer.ServeHTTP(w, r)
+
+					Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+					Expect(w.Result().Header.Get("Idempotent-Replayed")).To(Equal(replayed))
+					Expect(w.Result().Header.Get("Content-Type")).To(Equal(entity.ProblemContentType))
+				}
+			})
+		})
+
+		When("retry a create whose first attempt never finished", func() {
+			It("should take the key over once the lease ran out", func() {
+				body := []byte(`{"type": "Stale"}`)
+				hash := sha256.Sum256(append([]byte("POST /api/v1/categories/create\n"), body...))
+				record := entity.IdempotencyKey{
+					UserID:      userTest,
+					Key:         "stale-create-category",
+					RequestHash: hex.EncodeToString(hash[:]),
+					LockedUntil: time.Now().Add(time.Minute),
+					ExpiresAt:   time.Now().Add(time.Hour),
+				}
+				err := db.Create(&record).Error
+				Expect(err).To(BeNil())
+
+				create := func() *httptest.ResponseRecorder {
+					w := httptest.NewRecorder()
+					r := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader(body))
+					r.Header.Set("Content-Type", "application/json")
+					r.Header.Set("Idempotency-Key", "stale-create-category")
+					r.AddCookie(SetCookie(apiServer))
+					apiServer.ServeHTTP(w, r)
+					return w
+				}
+
+				w := create()
+				Expect(w.Result().StatusCode).To(Equal(http.StatusConflict))
+
+				err = db.Model(&record).Update("locked_until", time.Now().Add(-time.Second)).Error
+				Expect(err).To(BeNil())
+
+				w = create()
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(w.Result().Header.Get("Idempotent-Replayed")).To(BeEmpty())
+			})
+		})
+
+		When("reuse an Idempotency-Key with a different body", func() {
+			It("should return an unprocessable entity", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte(`{"type": "Other"}`)))
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("Idempotency-Key", "retry-create-category")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				errResp := entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(errResp.Error).To(Equal("idempotency key was already used for a different request"))
+			})
+		})
+	})
+
+	Describe("/api/v1/boards/events", func() {
+		When("a category is created while the board stream is open", func() {
+			It("should stream a category.created event", func() {
+				server := httptest.NewServer(apiServer)
+				defer server.Close()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				r, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/v1/boards/events", nil)
+				r.AddCookie(SetCookie(apiServer))
+				resp, err := http.DefaultClient.Do(r)
+				Expect(err).To(BeNil())
+				defer resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+				stream := bufio.NewReader(resp.Body)
+				line, err := stream.ReadString('\n')
+				Expect(err).To(BeNil())
+				Expect(line).To(Equal(": connected\n"))
+
+				w := httptest.NewRecorder()
+				create := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte(`{"type": "Streamed"}`)))
+				create.Header.Set("Content-Type", "application/json")
+				create.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, create)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+
+				var eventType string
+				event := entity.BoardEvent{}
+				for event.ID == 0 {
+					line, err := stream.ReadString('\n')
+					Expect(err).To(BeNil())
+
+					if strings.HasPrefix(line, "event: ") {
+						eventType = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+					}
+					if strings.HasPrefix(line, "data: ") {
+						err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event)
+						Expect(err).To(BeNil())
+					}
+				}
+
+				Expect(eventType).To(Equal(entity.EventCategoryCreated))
+				Expect(event.BoardID).To(Equal(userTest))
+				Expect(event.Category).ToNot(BeNil())
+				Expect(event.Category.Type).To(Equal("Streamed"))
+			})
+		})
+	})
+
+	Describe("/api/v1/boards/collab", func() {
+		When("two connections edit the same task", func() {
+			It("should share presence and refuse the second lock", func() {
+				server := httptest.NewServer(apiServer)
+				defer server.Close()
+
+				cookie := SetCookie(apiServer)
+				connect := func() *websocket.Conn {
+					config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/boards/collab", server.URL)
+					Expect(err).To(BeNil())
+					config.Header.Set("Cookie", cookie.Name+"="+cookie.Value)
+
+					conn, err := websocket.DialConfig(config)
+					Expect(err).To(BeNil())
+					return conn
+				}
+				receive := func(conn *websocket.Conn) entity.CollabMessage {
+					conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+					message := entity.CollabMessage{}
+					err := websocket.JSON.Receive(conn, &message)
+					Expect(err).To(BeNil())
+					return message
+				}
+
+				first := connect()
+				defer first.Close()
+
+				message := receive(first)
+				Expect(message.Type).To(Equal(entity.CollabPresence))
+				Expect(message.Presence).To(HaveLen(1))
+				Expect(message.Presence[0].UserID).To(Equal(userTest))
+
+				err := websocket.JSON.Send(first, entity.CollabMessage{Type: entity.CollabEdit, TaskID: taskIdTest})
+				Expect(err).To(BeNil())
+
+				message = receive(first)
+				Expect(message.Type).To(Equal(entity.CollabPresence))
+				Expect(message.Presence[0].EditingTaskID).To(Equal(taskIdTest))
+
+				second := connect()
+				defer second.Close()
+
+				message = receive(second)
+				Expect(message.Type).To(Equal(entity.CollabPresence))
+				Expect(message.Presence).To(HaveLen(2))
+
+				err = websocket.JSON.Send(second, entity.CollabMessage{Type: entity.CollabEdit, TaskID: taskIdTest})
+				Expect(err).To(BeNil())
+
+				message = receive(second)
+				Expect(message.Type).To(Equal(entity.CollabError))
+				Expect(message.Error).ToNot(BeNil())
+				Expect(message.Error.Code).To(Equal("task_locked"))
+			})
+		})
+	})
+
+	Describe("/webhooks", func() {
+		var receiver *httptest.Server
+		var received chan *http.Request
+		var receiverStatus int32
+		var webhookIdTest int
+
+		BeforeAll(func() {
+			received = make(chan *http.Request, 10)
+			atomic.StoreInt32(&receiverStatus, http.StatusInternalServerError)
+
+			receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				mac := hmac.New(sha256.New, []byte("0123456789abcdef"))
+				mac.Write(body)
+				if r.Header.Get(entity.WebhookSignatureHeader) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				received <- r
+				w.WriteHeader(int(atomic.LoadInt32(&receiverStatus)))
+			}))
+		})
+
+		AfterAll(func() {
+			receiver.Close()
+		})
+
+		deliveries := func() []entity.WebhookDelivery {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/webhooks/deliveries?webhook_id=%v", webhookIdTest), nil)
+			r.AddCookie(SetCookie(apiServer))
+			apiServer.ServeHTTP(w, r)
+
+			var resp []entity.WebhookDelivery
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			Expect(err).To(BeNil())
+			return resp
+		}
+
+		When("subscribe to an unknown event", func() {
+			It("should return a bad request", func() {
+				body, _ := json.Marshal(entity.WebhookRequest{URL: receiver.URL, Secret: "0123456789abcdef", Events: []string{"task.exploded"}})
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/webhooks/create", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(problem.Code).To(Equal("invalid_webhook"))
+			})
+		})
+
+		When("subscribe a URL on a private address", func() {
+			It("should be rejected unless private networks are allowed", func() {
+				webhookService := service.NewWebhookService(repository.NewWebhookRepository(db), service.NewEventBus(), time.Second, false)
+				for _, target := range []string{"http://169.254.169.254/latest/meta-data", "http://127.0.0.1:8080/", "http://[::1]/", "http://10.0.0.7/hook"} {
+					_, err := webhookService.StoreWebhook(context.Background(), &entity.Webhook{UserID: userTest, URL: target, Secret: "0123456789abcdef", Events: []string{entity.WebhookAllEvents}})
+					Expect(errors.Is(err, service.ErrInvalidWebhook)).To(BeTrue(), target)
+				}
+			})
+		})
+
+		When("a subscribed event happens", func() {
+			It("should post a signed delivery and log the response code", func() {
+				body, _ := json.Marshal(entity.WebhookRequest{URL: receiver.URL, Secret: "0123456789abcdef", Events: []string{entity.EventCategoryCreated}})
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/webhooks/create", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				var resp = map[string]interface{}{}
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				webhookIdTest = int(resp["webhook_id"].(float64))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte(`{"type": "Hooked"}`)))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+
+				var delivered *http.Request
+				Eventually(received, 5*time.Second).Should(Receive(&delivered))
+				Expect(delivered.Header.Get(entity.WebhookEventHeader)).To(Equal(entity.EventCategoryCreated))
+
+				Eventually(deliveries, 5*time.Second).Should(ContainElement(And(
+					HaveField("StatusCode", http.StatusInternalServerError),
+					HaveField("Attempts", 1),
+					HaveField("Status", entity.DeliveryPending),
+				)))
+			})
+		})
+
+		When("redeliver a failed delivery", func() {
+			It("should send it again as a new delivery", func() {
+				atomic.StoreInt32(&receiverStatus, http.StatusOK)
+				failed := deliveries()[0]
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/webhooks/deliveries/redeliver?delivery_id=%v", failed.ID), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				redelivery := entity.WebhookDelivery{}
+				err := json.NewDecoder(w.Body).Decode(&redelivery)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusAccepted))
+				Expect(*redelivery.RedeliveryOf).To(Equal(failed.ID))
+
+				Eventually(received, 5*time.Second).Should(Receive())
+				Eventually(deliveries, 5*time.Second).Should(ContainElement(And(
+					HaveField("ID", redelivery.ID),
+					HaveField("StatusCode", http.StatusOK),
+					HaveField("Status", entity.DeliverySucceeded),
+				)))
+			})
+		})
+	})
+
+	Describe("/api/v1/graphql", func() {
+		graphQL := func(query string, variables map[string]interface{}) (int, entity.GraphQLResponse) {
+			body, _ := json.Marshal(entity.GraphQLRequest{Query: query, Variables: variables})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/graphql", bytes.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			r.AddCookie(SetCookie(apiServer))
+			apiServer.ServeHTTP(w, r)
+
+			var resp entity.GraphQLResponse
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			Expect(err).To(BeNil())
+			return w.Result().StatusCode, resp
+		}
+
+		When("query the board with its categories, tasks and owner", func() {
+			It("should return all of them in one response", func() {
+				status, resp := graphQL(`query ($id: Int!) {
+					board(id: $id) {
+						id
+						task_count
+						owner { id }
+						categories { id task_count tasks { id category_id user { id } } }
+					}
+				}`, map[string]interface{}{"id": userTest})
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(resp.Errors).To(BeEmpty())
+
+				board := resp.Data.(map[string]interface{})["board"].(map[string]interface{})
+				Expect(board["id"]).To(BeEquivalentTo(userTest))
+				Expect(board["owner"]).To(HaveKeyWithValue("id", BeEquivalentTo(userTest)))
+
+				taskCount := 0
+				for _, category := range board["categories"].([]interface{}) {
+					category := category.(map[string]interface{})
+					for _, task := range category["tasks"].([]interface{}) {
+						Expect(task).To(HaveKeyWithValue("category_id", category["id"]))
+						Expect(task).To(HaveKeyWithValue("user", HaveKeyWithValue("id", BeEquivalentTo(userTest))))
+						taskCount++
+					}
+					Expect(category["task_count"]).To(BeEquivalentTo(len(category["tasks"].([]interface{}))))
+				}
+				Expect(board["task_count"]).To(BeEquivalentTo(taskCount))
+			})
+		})
+
+		When("query another user's board", func() {
+			It("should return a board_not_found error", func() {
+				status, resp := graphQL(`query ($id: Int!) { board(id: $id) { id } }`, map[string]interface{}{"id": userTest + 1})
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(resp.Errors).To(HaveLen(1))
+				Expect(resp.Errors[0].Extensions).To(HaveKeyWithValue("code", "board_not_found"))
+			})
+		})
+
+		When("create and move a task through mutations", func() {
+			It("should return the task in its new category", func() {
+				status, resp := graphQL(`mutation {
+					todo: createCategory(type: "GraphQL Todo") { id }
+					done: createCategory(type: "GraphQL Done") { id }
+				}`, nil)
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(resp.Errors).To(BeEmpty())
+
+				data := resp.Data.(map[string]interface{})
+				todoId := data["todo"].(map[string]interface{})["id"]
+				doneId := data["done"].(map[string]interface{})["id"]
+
+				status, resp = graphQL(`mutation ($category: Int!) {
+					createTask(input: {title: "Query", description: "Over GraphQL", category_id: $category, estimate: 2}) { id estimate category { type } }
+				}`, map[string]interface{}{"category": todoId})
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(resp.Errors).To(BeEmpty())
+
+				task := resp.Data.(map[string]interface{})["createTask"].(map[string]interface{})
+				Expect(task["estimate"]).To(BeEquivalentTo(2))
+				Expect(task["category"]).To(HaveKeyWithValue("type", "GraphQL Todo"))
+
+				status, resp = graphQL(`mutation ($id: Int!, $category: Int!) {
+					moveTask(id: $id, category_id: $category) { category { id type task_count } }
+				}`, map[string]interface{}{"id": task["id"], "category": doneId})
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(resp.Errors).To(BeEmpty())
+
+				category := resp.Data.(map[string]interface{})["moveTask"].(map[string]interface{})["category"]
+				Expect(category).To(HaveKeyWithValue("type", "GraphQL Done"))
+				Expect(category).To(HaveKeyWithValue("task_count", BeEquivalentTo(1)))
+			})
+		})
+	})
+
+	Describe("gRPC", func() {
+		var conn *grpc.ClientConn
+
+		BeforeAll(func() {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			go grpcServer.Serve(lis)
+
+			conn, err = grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			Expect(err).To(BeNil())
+		})
+
+		AfterAll(func() {
+			conn.Close()
+			grpcServer.Stop()
+		})
+
+		asUser := func(userId int) context.Context {
+			return metadata.AppendToOutgoingContext(context.Background(), "user_id", fmt.Sprint(userId))
+		}
+
+		When("call without a user id", func() {
+			It("should return UNAUTHENTICATED", func() {
+				_, err := kanbanv1.NewCategoryServiceClient(conn).GetBoard(context.Background(), &kanbanv1.GetBoardRequest{})
+				Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+			})
+		})
+
+		When("log in and create a task in a new category", func() {
+			It("should return the task on the board", func() {
+				login, err := kanbanv1.NewUserServiceClient(conn).Login(context.Background(), &kanbanv1.LoginRequest{Email: "test@mail.com", Password: "testing123"})
+				Expect(err).To(BeNil())
+				Expect(login.UserId).To(BeEquivalentTo(userTest))
+
+				ctx := asUser(int(login.UserId))
+				categories := kanbanv1.NewCategoryServiceClient(conn)
+				tasks := kanbanv1.NewTaskServiceClient(conn)
+
+				category, err := categories.CreateCategory(ctx, &kanbanv1.CreateCategoryRequest{Type: "gRPC Todo"})
+				Expect(err).To(BeNil())
+				Expect(category.Version).To(BeEquivalentTo(1))
+
+				task, err := tasks.CreateTask(ctx, &kanbanv1.CreateTaskRequest{Title: "Call", Description: "Over gRPC", CategoryId: category.Id, Estimate: 3})
+				Expect(err).To(BeNil())
+				Expect(task.CategoryId).To(Equal(category.Id))
+				Expect(task.CreatedAt).ToNot(BeNil())
+
+				title := "Called"
+				task, err = tasks.UpdateTask(ctx, &kanbanv1.UpdateTaskRequest{Id: task.Id, Title: &title, Version: task.Version})
+				Expect(err).To(BeNil())
+				Expect(task.Title).To(Equal("Called"))
+				Expect(task.Description).To(Equal("Over gRPC"))
+
+				board, err := categories.GetBoard(ctx, &kanbanv1.GetBoardRequest{})
+				Expect(err).To(BeNil())
+				Expect(board.Id).To(BeEquivalentTo(userTest))
+
+				var column *kanbanv1.BoardColumn
+				for _, c := range board.Columns {
+					if c.Category.Id == category.Id {
+						column = c
+					}
+				}
+				Expect(column).ToNot(BeNil())
+				Expect(column.Tasks).To(HaveLen(1))
+				Expect(column.Tasks[0].Id).To(Equal(task.Id))
+				Expect(column.TotalEstimate).To(BeEquivalentTo(3))
+			})
+		})
+
+		When("create a task without a title", func() {
+			It("should return INVALID_ARGUMENT with the field errors", func() {
+				_, err := kanbanv1.NewTaskServiceClient(conn).CreateTask(asUser(userTest), &kanbanv1.CreateTaskRequest{Description: "No title", CategoryId: int64(categoryIdForTaskTest)})
+				Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+				Expect(status.Convert(err).Message()).To(HavePrefix("validation_failed: "))
+				Expect(status.Convert(err).Message()).To(ContainSubstring("title is required"))
+			})
+		})
+
+		When("get a task of another user", func() {
+			It("should return NOT_FOUND", func() {
+				_, err := kanbanv1.NewTaskServiceClient(conn).GetTask(asUser(watcherTest), &kanbanv1.GetTaskRequest{Id: int64(taskIdTest)})
+				Expect(status.Code(err)).To(Equal(codes.NotFound))
+				Expect(status.Convert(err).Message()).To(Equal("task_not_found: task not found"))
+			})
+		})
+
+		When("a task is created while the board stream is open", func() {
+			It("should stream a task.created event", func() {
+				ctx, cancel := context.WithTimeout(asUser(userTest), 5*time.Second)
+				defer cancel()
+
+				stream, err := kanbanv1.NewCategoryServiceClient(conn).StreamBoardEvents(ctx, &kanbanv1.StreamBoardEventsRequest{})
+				Expect(err).To(BeNil())
+				// the headers arrive once the stream is subscribed
+				_, err = stream.Header()
+				Expect(err).To(BeNil())
+
+				task, err := kanbanv1.NewTaskServiceClient(conn).CreateTask(asUser(userTest), &kanbanv1.CreateTaskRequest{Title: "Streamed", Description: "Over gRPC", CategoryId: int64(categoryIdForTaskTest)})
+				Expect(err).To(BeNil())
+
+				event, err := stream.Recv()
+				Expect(err).To(BeNil())
+				Expect(event.Type).To(Equal(entity.EventTaskCreated))
+				Expect(event.BoardId).To(BeEquivalentTo(userTest))
+				Expect(event.TaskId).To(Equal(task.Id))
+				Expect(event.Task.Title).To(Equal("Streamed"))
+			})
+		})
+	})
+
+	Describe("/api/v1/boards/export", func() {
+		When("export the board and import it again", func() {
+			It("should recreate its categories and tasks with new ids", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/boards/export", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Result().Header.Get("Content-Disposition")).To(HavePrefix("attachment;"))
+
+				export := entity.BoardExport{}
+				err := json.NewDecoder(w.Body).Decode(&export)
+				Expect(err).To(BeNil())
+				Expect(export.Version).To(Equal(entity.BoardExportVersion))
+				Expect(export.Categories).ToNot(BeEmpty())
+
+				body, _ := json.Marshal(export)
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("POST", "/api/v1/boards/import", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				result := entity.BoardImportResult{}
+				err = json.NewDecoder(w.Body).Decode(&result)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(result.Categories).To(Equal(len(export.Categories)))
+				Expect(result.Tasks).To(Equal(len(export.Tasks)))
+				for sourceId, id := range result.CategoryIDs {
+					Expect(id).ToNot(Equal(sourceId))
+				}
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/boards/export", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				again := entity.BoardExport{}
+				err = json.NewDecoder(w.Body).Decode(&again)
+				Expect(err).To(BeNil())
+				Expect(again.Categories).To(HaveLen(2 * len(export.Categories)))
+				Expect(again.Tasks).To(HaveLen(2 * len(export.Tasks)))
+			})
+		})
+
+		When("import a task whose category is not in the file", func() {
+			It("should return the invalid fields and create nothing", func() {
+				export := entity.BoardExport{
+					Version:    entity.BoardExportVersion,
+					Categories: []entity.ExportCategory{{ID: 1, Type: "Imported"}},
+					Tasks: []entity.ExportTask{
+						{ID: 1, CategoryID: 1, Title: "Kept"},
+						{ID: 2, CategoryID: 2, Title: "Orphan"},
+					},
+				}
+				body, _ := json.Marshal(export)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/boards/import", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(problem.Errors).To(ConsistOf(entity.FieldError{Field: "tasks[1].category_id", Message: "must be the id of a category in the export"}))
+
+				var count int64
+				db.Model(&entity.Category{}).Where("type = ?", "Imported").Count(&count)
+				Expect(count).To(BeZero())
+			})
+		})
+	})
+
+	Describe("/api/v1/boards/import/trello", func() {
+		trelloBoard := `{
+			"name": "Roadmap",
+			"lists": [
+				{"id": "l2", "name": "Trello Done", "pos": 2},
+				{"id": "l1", "name": "Trello Todo", "pos": 1},
+				{"id": "l3", "name": "Old", "closed": true, "pos": 3}
+			],
+			"cards": [
+				{"id": "c1", "name": "Write spec", "desc": "First draft", "idList": "l1", "pos": 1, "due": "2026-03-01T12:00:00.000Z", "labels": [{"name": "docs", "color": "blue"}, {"name": "", "color": "red"}]},
+				{"id": "c2", "name": "Ship it", "idList": "l2", "pos": 1, "closed": true},
+				{"id": "c3", "name": "Forgotten", "idList": "l3", "pos": 1}
+			],
+			"checklists": [
+				{"id": "k1", "idCard": "c1", "name": "Steps", "checkItems": [{"name": "Outline", "state": "complete", "pos": 1}, {"name": "Review", "state": "incomplete", "pos": 2}]}
+			],
+			"actions": [
+				{"type": "commentCard", "date": "2026-02-01T09:00:00.000Z", "data": {"text": "Looks good", "card": {"id": "c1"}}},
+				{"type": "updateCard", "date": "2026-02-02T09:00:00.000Z", "data": {"card": {"id": "c1"}}}
+			]
+		}`
+
+		When("import a Trello board as a dry run", func() {
+			It("should report the lists and cards without creating them", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/boards/import/trello?dry_run=true", strings.NewReader(trelloBoard))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				report := entity.TrelloImportReport{}
+				err := json.NewDecoder(w.Body).Decode(&report)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(report.DryRun).To(BeTrue())
+				Expect(report.Result).To(BeNil())
+				Expect(report.Categories).To(Equal([]entity.TrelloImportColumn{
+					{Name: "Trello Todo", Tasks: []string{"Write spec"}},
+					{Name: "Trello Done", Tasks: []string{"Ship it"}},
+				}))
+				Expect(report.Tasks).To(Equal(2))
+				Expect(report.ArchivedTasks).To(Equal(1))
+				Expect(report.Comments).To(Equal(1))
+				Expect(report.ChecklistItems).To(Equal(2))
+				Expect(report.DueDates).To(Equal(1))
+				Expect(report.Warnings).To(HaveLen(1))
+
+				var count int64
+				db.Model(&entity.Category{}).Where("type = ?", "Trello Todo").Count(&count)
+				Expect(count).To(BeZero())
+			})
+		})
+
+		When("import a Trello board", func() {
+			It("should create the lists as categories and the cards as tasks", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/boards/import/trello", strings.NewReader(trelloBoard))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				report := entity.TrelloImportReport{}
+				err := json.NewDecoder(w.Body).Decode(&report)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(report.Result).ToNot(BeNil())
+				Expect(report.Result.Categories).To(Equal(2))
+				Expect(report.Result.Tasks).To(Equal(2))
+				Expect(report.Result.CustomFields).To(BeZero())
+
+				task := entity.Task{}
+				err = db.Where("title = ? AND user_id = ?", "Write spec", userTest).First(&task).Error
+				Expect(err).To(BeNil())
+				Expect(task.Description).To(Equal("First draft\n\n### Steps\n- [x] Outline\n- [ ] Review"))
+				Expect([]string(task.Labels)).To(Equal([]string{"docs", "red"}))
+				Expect(task.DueDate).ToNot(BeNil())
+				Expect(task.DueDate.Format(entity.DateLayout)).To(Equal("2026-03-01"))
+			})
+		})
+
+		When("import a file without lists", func() {
+			It("should return invalid_trello_board", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/boards/import/trello", strings.NewReader(`{"name": "Empty"}`))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(problem.Code).To(Equal("invalid_trello_board"))
+			})
+		})
+	})
+
+	Describe("/api/v1/tasks/import", func() {
+		When("import CSV rows into a missing category and export them again", func() {
+			It("should create the category and the tasks, then list them as CSV", func() {
+				req := entity.TaskCSVImportRequest{
+					CSV:              "Name,List,Points,Tags\nCSV spreadsheet task,CSV Column,3,finance; q3\nCSV second task,csv column,,\n",
+					Mapping:          map[string]string{"title": "Name", "category": "List", "estimate": "Points", "labels": "Tags"},
+					CreateCategories: true,
+				}
+				body, _ := json.Marshal(req)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/import", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				result := entity.TaskCSVImportResult{}
+				err := json.NewDecoder(w.Body).Decode(&result)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(result.Tasks).To(Equal(2))
+				Expect(result.CreatedCategories).To(HaveLen(1))
+				Expect(result.CreatedCategories[0].Type).To(Equal("CSV Column"))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/tasks/export?columns=title,category,estimate,labels&q=spreadsheet", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Result().Header.Get("Content-Type")).To(HavePrefix("text/csv"))
+
+				records, err := csv.NewReader(w.Body).ReadAll()
+				Expect(err).To(BeNil())
+				Expect(records).To(Equal([][]string{
+					{"title", "category", "estimate", "labels"},
+					{"CSV spreadsheet task", "CSV Column", "3", "finance;q3"},
+				}))
+			})
+		})
+
+		When("export tasks whose text starts like a formula", func() {
+			It("should prefix those cells with a quote", func() {
+				req := entity.TaskCSVImportRequest{
+					CSV:              "Name,Notes,List,Tags\n\"=HYPERLINK(\"\"http://example.com\"\")\",+1 more,-CSV Formulas,@risk\n",
+					Mapping:          map[string]string{"title": "Name", "description": "Notes", "category": "List", "labels": "Tags"},
+					CreateCategories: true,
+				}
+				body, _ := json.Marshal(req)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/import", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				result := entity.TaskCSVImportResult{}
+				err := json.NewDecoder(w.Body).Decode(&result)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(result.CreatedCategories).To(HaveLen(1))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/tasks/export?columns=title,description,category,labels&category_id=%d", result.CreatedCategories[0].ID), nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+				records, err := csv.NewReader(w.Body).ReadAll()
+				Expect(err).To(BeNil())
+				Expect(records).To(Equal([][]string{
+					{"title", "description", "category", "labels"},
+					{`'=HYPERLINK("http://example.com")`, "'+1 more", "'-CSV Formulas", "'@risk"},
+				}))
+			})
+		})
+
+		When("a task cannot be stored after its category is created", func() {
+			It("should roll back the category as well", func() {
+				req := entity.TaskCSVImportRequest{
+					CSV:              "Name,List\nCSV stored row,CSV Rollback\nCSV broken\x00row,CSV Rollback\n",
+					Mapping:          map[string]string{"title": "Name", "category": "List"},
+					CreateCategories: true,
+				}
+				body, _ := json.Marshal(req)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/import", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+
+				var count int64
+				db.Model(&entity.Category{}).Where("type = ?", "CSV Rollback").Count(&count)
+				Expect(count).To(BeZero())
+				db.Model(&entity.Task{}).Where("title = ?", "CSV stored row").Count(&count)
+				Expect(count).To(BeZero())
+			})
+		})
+
+		When("import rows with invalid values", func() {
+			It("should return the invalid rows and create nothing", func() {
+				req := entity.TaskCSVImportRequest{
+					CSV:     "Name,List,Points\nCSV valid row,CSV Column,1\nCSV invalid row,CSV Nowhere,lots\n",
+					Mapping: map[string]string{"title": "Name", "category": "List", "estimate": "Points"},
+				}
+				body, _ := json.Marshal(req)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/tasks/import", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(problem.Errors).To(ConsistOf(
+					entity.FieldError{Field: "rows[3].estimate", Message: "must be a number from 0 to 1000"},
+					entity.FieldError{Field: "rows[3].category", Message: `category "CSV Nowhere" does not exist, set create_categories to create it`},
+				))
+
+				var count int64
+				db.Model(&entity.Task{}).Where("title = ?", "CSV valid row").Count(&count)
+				Expect(count).To(BeZero())
+			})
+		})
+	})
+
+	Describe("/api/v1/boards/markdown", func() {
+		When("render the completed tasks of today grouped by label", func() {
+			It("should list the done column as a checked list under its labels", func() {
+				category := entity.Category{}
+				err := db.Where("type = ? AND user_id = ?", entity.DoneCategory, userTest).First(&category).Error
+				Expect(err).To(BeNil())
+
+				task := entity.Task{Title: "Markdown release note", Description: "shipped", CategoryID: category.ID, UserID: userTest, Labels: []string{"release"}}
+				err = db.Create(&task).Error
+				Expect(err).To(BeNil())
+
+				today := time.Now().UTC().Format(entity.DateLayout)
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/boards/markdown?from="+today+"&group_by=label", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Result().Header.Get("Content-Type")).To(HavePrefix("text/markdown"))
+				markdown := w.Body.String()
+				Expect(markdown).To(HavePrefix("# Completed tasks\n"))
+				Expect(markdown).To(ContainSubstring("## Done\n"))
+				Expect(markdown).To(ContainSubstring("### release\n\n- [x] Markdown release note\n"))
+				Expect(markdown).ToNot(ContainSubstring("## Todo"))
+			})
+		})
+
+		When("render the board with an unknown grouping", func() {
+			It("should return invalid_markdown_options", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/boards/markdown?group_by=color", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err := json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(problem.Code).To(Equal("invalid_markdown_options"))
+			})
+		})
+	})
+
+	Describe("/api/v1/calendar/token", func() {
+		When("subscribe to the feed and regenerate its token", func() {
+			It("should serve the due tasks to the token and revoke the old one", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/calendar/token", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+
+				feed := entity.CalendarFeedResponse{}
+				err := json.NewDecoder(w.Body).Decode(&feed)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+				Expect(feed.Token).To(HaveLen(64))
+				Expect(feed.URL).To(HaveSuffix("/api/v1/calendar/feed.ics?token=" + feed.Token))
+
+				category := entity.Category{}
+				err = db.Where("user_id = ?", userTest).First(&category).Error
+				Expect(err).To(BeNil())
+
+				due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+				task := entity.Task{Title: "Calendar release", CategoryID: category.ID, UserID: userTest, DueDate: &due}
+				err = db.Create(&task).Error
+				Expect(err).To(BeNil())
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/calendar/feed.ics?token="+feed.Token, nil)
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Result().Header.Get("Content-Type")).To(HavePrefix("text/calendar"))
+				ics := w.Body.String()
+				Expect(ics).To(HavePrefix("BEGIN:VCALENDAR\r\n"))
+				Expect(ics).To(ContainSubstring(fmt.Sprintf("UID:task-%d@kanban-app\r\n", task.ID)))
+				Expect(ics).To(ContainSubstring("DTSTART;VALUE=DATE:20260301\r\n"))
+				Expect(ics).To(ContainSubstring("SUMMARY:Calendar release\r\n"))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("POST", "/api/v1/calendar/token", nil)
+				r.AddCookie(SetCookie(apiServer))
+				apiServer.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/api/v1/calendar/feed.ics?token="+feed.Token, nil)
+				apiServer.ServeHTTP(w, r)
+
+				problem := entity.Problem{}
+				err = json.NewDecoder(w.Body).Decode(&problem)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+				Expect(problem.Code).To(Equal("calendar_feed_not_found"))
+			})
+		})
+	})
+
+	Describe("/api/openapi.json", func() {
+		When("fetch the OpenAPI document", func() {
+			It("should document every registered route", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/openapi.json", nil)
+				apiServer.ServeHTTP(w, r)
+
+				doc := entity.OpenAPI{}
+				err := json.NewDecoder(w.Body).Decode(&doc)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(doc.OpenAPI).To(HavePrefix("3."))
+
+				routes := main.RegisteredRoutes()
+				Expect(routes).ToNot(BeEmpty())
+				registered := map[string]bool{}
+				for _, route := range routes {
+					Expect(doc.Paths).To(HaveKey(route.Path), "route %s %s is missing from the spec", route.Method, route.Path)
+					Expect(doc.Paths[route.Path]).To(HaveKey(strings.ToLower(route.Method)), "route %s %s is missing from the spec", route.Method, route.Path)
+					registered[route.Method+" "+route.Path] = true
+				}
+
+				for _, documented := range main.DocumentedRoutes() {
+					Expect(registered).To(HaveKey(documented), "documented route %s is not registered", documented)
+				}
+
+				getTask := doc.Paths["/api/v1/tasks/get"]["get"].Responses["200"].Content["application/json"].Schema
+				Expect(getTask.OneOf).To(HaveLen(2))
+				Expect(getTask.OneOf[0].Ref).To(Equal("#/components/schemas/TaskPage"))
+				Expect(getTask.OneOf[1].Ref).To(Equal("#/components/schemas/Task"))
+			})
+		})
+
+		When("open the docs page", func() {
+			It("should return the html page", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/docs", nil)
+				apiServer.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Result().Header.Get("Content-Type")).To(HavePrefix("text/html"))
+				Expect(w.Body.String()).To(ContainSubstring("/api/openapi.json"))
+			})
+		})
+	})
+
+	Describe("/tasks/delete", func() {
+		When("hit endpoint without user login", func() {
+			It("should return an error unauthorized", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("DELETE", "/api/v1/tasks/delete", nil)
+				r.Header.Set("Content-Type", "application/json")
+
+				apiServer.ServeHTTP(w, r)
+
+				var errResp = entity.ErrorResponse{}
+				err := json.NewDecoder(w.Body).Decode(&errResp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(errResp.Error).To(Equal("error unauthorized user id"))
+			})
+		})
+
+		When("hit endpoint with GET method", func() {
+			It("should return a success", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/api/v1/tasks/delete", bytes.NewReader([]byte("{}")))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = entity.ErrorResponse{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusMethodNotAllowed))
+				Expect(resp.Error).To(Equal("method is not allowed!"))
+			})
+		})
+
+		When("hit endpoint with DELETE method", func() {
+			It("should return a success", func() {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/tasks/delete?task_id=%v", taskIdTest), nil)
+				r.AddCookie(SetCookie(apiServer))
+
+				apiServer.ServeHTTP(w, r)
+
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(int(resp["task_id"].(float64))).To(Equal(taskIdTest))
+				Expect(resp["message"]).To(Equal("success delete task"))
+			})
+		})
+	})
+})
+
+var _ = Describe("TestWebHandler", Ordered, func() {
+	var clientHandler *http.ServeMux
+	var db *gorm.DB
+	var userClientID int
+
+	BeforeAll(func() {
+		err := repository.ConnectDB()
+		if err != nil {
+			panic(err)
+		}
+
+		db = repository.GetDBConnection()
+
+		clientHandler = http.NewServeMux()
+
+		clientHandler = main.RunServer(db, clientHandler, grpc.NewServer())
+		clientHandler = main.RunClient(clientHandler, main.Resources)
+
+		register := entity.UserRegister{
+			Fullname: "testing client",
+			Email:    "test@mail.com",
+			Password: "testing123",
+		}
+
+		byte, _ := json.Marshal(register)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/v1/users/register", bytes.NewReader(byte))
+		r.Header.Set("Content-Type", "application/json")
+
+		clientHandler.ServeHTTP(w, r)
+
+		var resp = map[string]interface{}{}
+		err = json.Unmarshal(w.Body.Bytes(), &resp)
+		if err != nil {
+			panic(err)
+		}
+
+		userClientID = int(resp["user_id"].(float64))
+	})
 
 	AfterAll(func() {
 		ctx := context.Background()
 
-		err := db.WithContext(ctx).Exec("DELETE FROM users WHERE id = ?", userClientID).Error
+		err := db.WithContext(ctx).Exec("DELETE FROM notifications WHERE user_id = ?", userClientID).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM tasks WHERE user_id = ?", userClientID).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM categories WHERE user_id = ?", userClientID).Error
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.WithContext(ctx).Exec("DELETE FROM users WHERE id = ?", userClientID).Error
 		if err != nil {
 			panic(err)
 		}
@@ -846,4 +2871,84 @@ var _ = Describe("TestWebHandler", Ordered, func() {
 			})
 		})
 	})
+
+	Describe("/notifications", func() {
+		When("a notification message holds markup from a task title", func() {
+			It("should escape the message", func() {
+				server := httptest.NewServer(clientHandler)
+				defer server.Close()
+
+				baseURL := config.AppConfig.BaseURL
+				config.AppConfig.BaseURL = server.URL
+				defer func() { config.AppConfig.BaseURL = baseURL }()
+
+				err := db.Create(&entity.Notification{
+					UserID:  userClientID,
+					ActorID: userClientID,
+					TaskID:  1,
+					Type:    entity.NotificationEdited,
+					Message: `Task "<script>alert(1)</script>" was edited`,
+				}).Error
+				Expect(err).To(BeNil())
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/notifications", nil)
+				r.AddCookie(SetCookie(clientHandler))
+
+				clientHandler.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).To(ContainSubstring("&lt;script&gt;alert(1)&lt;/script&gt;"))
+				Expect(w.Body.String()).NotTo(ContainSubstring("<script>alert(1)"))
+			})
+		})
+	})
+
+	Describe("/search", func() {
+		When("a matching task title holds markup", func() {
+			It("should escape the title", func() {
+				server := httptest.NewServer(clientHandler)
+				defer server.Close()
+
+				baseURL := config.AppConfig.BaseURL
+				config.AppConfig.BaseURL = server.URL
+				defer func() { config.AppConfig.BaseURL = baseURL }()
+
+				cookie := SetCookie(clientHandler)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest("POST", "/api/v1/categories/create", bytes.NewReader([]byte(`{"type": "Escaped"}`)))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(cookie)
+				clientHandler.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+
+				var resp = map[string]interface{}{}
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				Expect(err).To(BeNil())
+
+				body, _ := json.Marshal(entity.TaskRequest{
+					Title:       "<script>alert(1)</script> release",
+					Description: "release notes",
+					CategoryID:  int(resp["category_id"].(float64)),
+				})
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("POST", "/api/v1/tasks/create", bytes.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.AddCookie(cookie)
+				clientHandler.ServeHTTP(w, r)
+				Expect(w.Result().StatusCode).To(Equal(http.StatusCreated))
+
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/search?q=release", nil)
+				r.AddCookie(cookie)
+
+				clientHandler.ServeHTTP(w, r)
+
+				Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).To(ContainSubstring("&lt;script&gt;alert(1)&lt;/script&gt; release"))
+				Expect(w.Body.String()).NotTo(ContainSubstring("<script>alert(1)"))
+			})
+		})
+	})
 })
@@ -2,10 +2,9 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 
-	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/utils"
 )
 
 func Auth(next http.Handler) http.Handler {
@@ -15,8 +14,7 @@ func Auth(next http.Handler) http.Handler {
 
 		if err != nil {
 			if headerType == "application/json" {
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(entity.NewErrorResponse("error unauthorized user id"))
+				utils.WriteError(w, http.StatusUnauthorized, "error unauthorized user id")
 				return
 			} else {
 				http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -28,3 +26,16 @@ func Auth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// BoardOwner only lets users reach their own board. A board is identified
+// by its owner's user id, passed as the board_id query parameter.
+func BoardOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("board_id") != r.Context().Value("id").(string) {
+			utils.WriteError(w, http.StatusNotFound, "board not found")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
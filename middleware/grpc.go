@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcPublicMethods are the gRPC methods callable without a user id.
+var grpcPublicMethods = map[string]bool{
+	"/kanban.v1.UserService/Login":    true,
+	"/kanban.v1.UserService/Register": true,
+}
+
+// GRPCAuth is Auth for unary gRPC calls: the user id comes from the
+// "user_id" metadata key instead of the cookie and lands in the context
+// under the same key.
+func GRPCAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if grpcPublicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	ctx, err := grpcUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// GRPCStreamAuth is GRPCAuth for streaming calls.
+func GRPCStreamAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if grpcPublicMethods[info.FullMethod] {
+		return handler(srv, stream)
+	}
+
+	ctx, err := grpcUserContext(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, authStream{stream, ctx})
+}
+
+func grpcUserContext(ctx context.Context) (context.Context, error) {
+	values := metadata.ValueFromIncomingContext(ctx, "user_id")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "error unauthorized user id")
+	}
+
+	return context.WithValue(ctx, "id", values[0]), nil
+}
+
+// authStream hands the authenticated context to stream handlers.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/service"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// Idempotency replays the stored response when a request is retried with
+// the same Idempotency-Key header. It must run after Auth, keys are scoped
+// to the logged in user.
+func Idempotency(idempotencyService service.IdempotencyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(entity.IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > entity.MaxIdempotencyKeyLength {
+				utils.WriteError(w, http.StatusBadRequest, "invalid idempotency key")
+				return
+			}
+
+			userId, _ := strconv.Atoi(r.Context().Value("id").(string))
+
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			hash := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+
+			record, err := idempotencyService.Begin(r.Context(), userId, key, hex.EncodeToString(hash[:]))
+			switch {
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				utils.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			case errors.Is(err, service.ErrIdempotencyInProgress):
+				utils.WriteError(w, http.StatusConflict, err.Error())
+				return
+			case err != nil:
+				log.Println(err.Error())
+				utils.WriteError(w, http.StatusInternalServerError, "error internal server")
+				return
+			}
+
+			if record.StatusCode != 0 {
+				for _, line := range record.ResponseHeader {
+					parts := strings.SplitN(line, ": ", 2)
+					if len(parts) == 2 {
+						w.Header().Set(parts[0], parts[1])
+					}
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.ResponseBody)
+				return
+			}
+
+			// a panicking handler gives the key back instead of leaving it
+			// in progress until its lease runs out
+			defer func() {
+				if p := recover(); p != nil {
+					err := idempotencyService.Release(r.Context(), record.ID)
+					if err != nil {
+						log.Println("release idempotency key:", err.Error())
+					}
+					panic(p)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// server errors are not stored so the client can retry them
+			if recorder.statusCode >= http.StatusInternalServerError {
+				err = idempotencyService.Release(r.Context(), record.ID)
+			} else {
+				err = idempotencyService.Complete(r.Context(), record.ID, recorder.statusCode, replayedHeader(w.Header()), recorder.body.Bytes())
+			}
+			if err != nil {
+				log.Println("store idempotency key:", err.Error())
+			}
+		})
+	}
+}
+
+// replayedHeaders are the response headers stored with a key and sent
+// again on a replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+func replayedHeader(header http.Header) []string {
+	var lines []string
+	for _, name := range replayedHeaders {
+		if value := header.Get(name); value != "" {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return lines
+}
+
+// responseRecorder passes the response through while keeping a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if !rec.wroteHeader {
+		rec.statusCode = statusCode
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -1,17 +1,15 @@
 package middleware
 
 import (
-	"encoding/json"
 	"net/http"
 
-	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/utils"
 )
 
 func Get(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(entity.NewErrorResponse("method is not allowed!"))
+			utils.WriteError(w, http.StatusMethodNotAllowed, "method is not allowed!")
 			return
 		}
 
@@ -23,8 +21,7 @@ func Get(next http.Handler) http.Handler {
 func Post(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(entity.NewErrorResponse("method is not allowed!"))
+			utils.WriteError(w, http.StatusMethodNotAllowed, "method is not allowed!")
 			return
 		}
 
@@ -36,8 +33,7 @@ func Post(next http.Handler) http.Handler {
 func Patch(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPatch {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(entity.NewErrorResponse("method is not allowed!"))
+			utils.WriteError(w, http.StatusMethodNotAllowed, "method is not allowed!")
 			return
 		}
 
@@ -49,8 +45,7 @@ func Patch(next http.Handler) http.Handler {
 func Put(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPut {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(entity.NewErrorResponse("method is not allowed!"))
+			utils.WriteError(w, http.StatusMethodNotAllowed, "method is not allowed!")
 			return
 		}
 
@@ -62,8 +57,7 @@ func Put(next http.Handler) http.Handler {
 func Delete(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(entity.NewErrorResponse("method is not allowed!"))
+			utils.WriteError(w, http.StatusMethodNotAllowed, "method is not allowed!")
 			return
 		}
 
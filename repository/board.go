@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+)
+
+type BoardRepository interface {
+	GetBoardSnapshot(ctx context.Context, userId int) (entity.BoardSnapshot, error)
+	ImportBoard(ctx context.Context, userId int, board entity.BoardSnapshot) (entity.BoardImportResult, error)
+}
+
+type boardRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardRepository(db *gorm.DB) BoardRepository {
+	return &boardRepository{db}
+}
+
+// GetBoardSnapshot reads everything on the user's board, archived tasks
+// included. Running timers are left out.
+func (r *boardRepository) GetBoardSnapshot(ctx context.Context, userId int) (entity.BoardSnapshot, error) {
+	var board entity.BoardSnapshot
+	db := r.db.WithContext(ctx)
+
+	err := db.Where("user_id = ?", userId).Order("id").Find(&board.Categories).Error
+	if err != nil {
+		return board, err
+	}
+
+	err = db.Where("user_id = ?", userId).Order("id").Find(&board.CustomFields).Error
+	if err != nil {
+		return board, err
+	}
+
+	err = db.Where("user_id = ?", userId).Order("id").Find(&board.Tasks).Error
+	if err != nil {
+		return board, err
+	}
+
+	if len(board.Tasks) == 0 {
+		return board, nil
+	}
+
+	taskIds := db.Model(&entity.Task{}).Select("id").Where("user_id = ?", userId)
+
+	err = db.Where("task_id IN (?)", taskIds).Order("id").Find(&board.Values).Error
+	if err != nil {
+		return board, err
+	}
+
+	err = db.Where("task_id IN (?)", taskIds).Order("id").Find(&board.Comments).Error
+	if err != nil {
+		return board, err
+	}
+
+	err = db.Where("task_id IN (?) AND ended_at IS NOT NULL", taskIds).Order("id").Find(&board.TimeEntries).Error
+	return board, err
+}
+
+// ImportBoard creates the board's records for userId in one transaction.
+// The ids in board link its records together, every record is created with
+// a new id and the references are rewritten to match.
+func (r *boardRepository) ImportBoard(ctx context.Context, userId int, board entity.BoardSnapshot) (entity.BoardImportResult, error) {
+	result := entity.BoardImportResult{
+		CategoryIDs:    map[int]int{},
+		CustomFieldIDs: map[int]int{},
+		TaskIDs:        map[int]int{},
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, category := range board.Categories {
+			sourceId := category.ID
+			category.ID = 0
+			category.UserID = userId
+			category.Version = 0
+
+			err := tx.Create(&category).Error
+			if err != nil {
+				return err
+			}
+			result.CategoryIDs[sourceId] = category.ID
+		}
+
+		for _, field := range board.CustomFields {
+			sourceId := field.ID
+			field.ID = 0
+			field.UserID = userId
+
+			err := tx.Create(&field).Error
+			if err != nil {
+				return err
+			}
+			result.CustomFieldIDs[sourceId] = field.ID
+		}
+
+		for _, task := range board.Tasks {
+			sourceId := task.ID
+			task.ID = 0
+			task.UserID = userId
+			task.CategoryID = result.CategoryIDs[task.CategoryID]
+			task.AssigneeID = nil
+			task.Version = 0
+			if task.Labels == nil {
+				task.Labels = pq.StringArray{}
+			}
+
+			err := tx.Create(&task).Error
+			if err != nil {
+				return err
+			}
+			result.TaskIDs[sourceId] = task.ID
+		}
+
+		values := make([]entity.CustomFieldValue, len(board.Values))
+		for i, value := range board.Values {
+			value.ID = 0
+			value.TaskID = result.TaskIDs[value.TaskID]
+			value.FieldID = result.CustomFieldIDs[value.FieldID]
+			values[i] = value
+		}
+
+		comments := make([]entity.Comment, len(board.Comments))
+		for i, comment := range board.Comments {
+			comment.ID = 0
+			comment.TaskID = result.TaskIDs[comment.TaskID]
+			comment.UserID = userId
+			comments[i] = comment
+		}
+
+		entries := make([]entity.TimeEntry, len(board.TimeEntries))
+		for i, entry := range board.TimeEntries {
+			entry.ID = 0
+			entry.TaskID = result.TaskIDs[entry.TaskID]
+			entry.UserID = userId
+			entries[i] = entry
+		}
+
+		for _, rows := range []interface{}{&values, &comments, &entries} {
+			err := tx.CreateInBatches(rows, 100).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.BoardImportResult{}, err
+	}
+
+	result.Categories = len(result.CategoryIDs)
+	result.CustomFields = len(result.CustomFieldIDs)
+	result.Tasks = len(result.TaskIDs)
+	result.Comments = len(board.Comments)
+	result.TimeEntries = len(board.TimeEntries)
+	return result, nil
+}
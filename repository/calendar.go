@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CalendarRepository interface {
+	GetFeedByUserID(ctx context.Context, userId int) (entity.CalendarFeed, error)
+	GetFeedByToken(ctx context.Context, token string) (entity.CalendarFeed, error)
+	SaveFeed(ctx context.Context, feed *entity.CalendarFeed) error
+	DeleteFeed(ctx context.Context, userId int) error
+}
+
+type calendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &calendarRepository{db}
+}
+
+func (r *calendarRepository) GetFeedByUserID(ctx context.Context, userId int) (entity.CalendarFeed, error) {
+	var feed entity.CalendarFeed
+	err := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&feed).Error
+	return feed, err
+}
+
+func (r *calendarRepository) GetFeedByToken(ctx context.Context, token string) (entity.CalendarFeed, error) {
+	var feed entity.CalendarFeed
+	err := r.db.WithContext(ctx).Where("token = ?", token).Find(&feed).Error
+	return feed, err
+}
+
+// SaveFeed creates the user's feed or replaces the token of the existing
+// one.
+func (r *calendarRepository) SaveFeed(ctx context.Context, feed *entity.CalendarFeed) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "updated_at"}),
+	}).Create(feed).Error
+}
+
+func (r *calendarRepository) DeleteFeed(ctx context.Context, userId int) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userId).Delete(&entity.CalendarFeed{}).Error
+}
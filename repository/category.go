@@ -13,8 +13,9 @@ type CategoryRepository interface {
 	StoreCategory(ctx context.Context, category *entity.Category) (categoryId int, err error)
 	StoreManyCategory(ctx context.Context, categories []entity.Category) error
 	GetCategoryByID(ctx context.Context, id int) (entity.Category, error)
+	GetCategoriesByIDs(ctx context.Context, ids []int) ([]entity.Category, error)
 	UpdateCategory(ctx context.Context, category *entity.Category) error
-	DeleteCategory(ctx context.Context, id int) error
+	DeleteCategory(ctx context.Context, id int, version int) (deletedTasks []entity.Task, err error)
 }
 
 type categoryRepository struct {
@@ -49,10 +50,55 @@ func (r *categoryRepository) GetCategoryByID(ctx context.Context, id int) (entit
 	return category, err
 }
 
+func (r *categoryRepository) GetCategoriesByIDs(ctx context.Context, ids []int) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// UpdateCategory writes the non-zero fields of the category. A non-zero
+// Version must match the stored one and is replaced by the new version.
 func (r *categoryRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
-	return r.db.WithContext(ctx).Model(&category).Updates(&category).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := bumpVersion(tx, "categories", category.ID, category.Version)
+		if err != nil {
+			return err
+		}
+
+		category.Version = version
+		return tx.Model(&category).Omit("version").Updates(&category).Error
+	})
 }
 
-func (r *categoryRepository) DeleteCategory(ctx context.Context, id int) error {
-	return r.db.WithContext(ctx).Delete(&entity.Category{}, id).Error
+// DeleteCategory removes the category together with its tasks in one
+// transaction and returns the tasks it removed.
+func (r *categoryRepository) DeleteCategory(ctx context.Context, id int, version int) (deletedTasks []entity.Task, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if version != 0 {
+			_, err := bumpVersion(tx, "categories", id, version)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Where("category_id = ?", id).Find(&deletedTasks).Error
+		if err != nil {
+			return err
+		}
+
+		if len(deletedTasks) > 0 {
+			ids := make([]int, len(deletedTasks))
+			for i, task := range deletedTasks {
+				ids[i] = task.ID
+			}
+
+			err = deleteTasks(tx, ids)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&entity.Category{}, id).Error
+	})
+	return deletedTasks, err
 }
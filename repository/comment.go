@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository interface {
+	StoreComment(ctx context.Context, comment *entity.Comment) (commentId int, err error)
+	GetCommentsByTaskID(ctx context.Context, taskId int) ([]entity.Comment, error)
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db}
+}
+
+func (r *commentRepository) StoreComment(ctx context.Context, comment *entity.Comment) (commentId int, err error) {
+	err = r.db.WithContext(ctx).Create(&comment).Error
+	if err != nil {
+		return 0, err
+	}
+	return comment.ID, nil
+}
+
+func (r *commentRepository) GetCommentsByTaskID(ctx context.Context, taskId int) ([]entity.Comment, error) {
+	var comments []entity.Comment
+	err := r.db.WithContext(ctx).Where("task_id = ?", taskId).Order("created_at").Find(&comments).Error
+	return comments, err
+}
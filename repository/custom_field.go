@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomFieldRepository interface {
+	GetCustomFieldsByUserId(ctx context.Context, id int) ([]entity.CustomField, error)
+	GetCustomFieldByID(ctx context.Context, id int) (entity.CustomField, error)
+	StoreCustomField(ctx context.Context, field *entity.CustomField) (fieldId int, err error)
+	DeleteCustomField(ctx context.Context, id int) error
+	GetValuesByTaskIDs(ctx context.Context, taskIds []int) ([]entity.CustomFieldValue, error)
+	StoreValues(ctx context.Context, values []entity.CustomFieldValue) error
+	DeleteValues(ctx context.Context, taskId int, fieldIds []int) error
+}
+
+type customFieldRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomFieldRepository(db *gorm.DB) CustomFieldRepository {
+	return &customFieldRepository{db}
+}
+
+func (r *customFieldRepository) GetCustomFieldsByUserId(ctx context.Context, id int) ([]entity.CustomField, error) {
+	var fields []entity.CustomField
+	err := r.db.WithContext(ctx).Where("user_id = ?", id).Order("id").Find(&fields).Error
+	return fields, err
+}
+
+func (r *customFieldRepository) GetCustomFieldByID(ctx context.Context, id int) (entity.CustomField, error) {
+	var field entity.CustomField
+	err := r.db.WithContext(ctx).Find(&field, id).Error
+	return field, err
+}
+
+func (r *customFieldRepository) StoreCustomField(ctx context.Context, field *entity.CustomField) (fieldId int, err error) {
+	err = r.db.WithContext(ctx).Create(&field).Error
+	if err != nil {
+		return 0, err
+	}
+	return field.ID, nil
+}
+
+func (r *customFieldRepository) DeleteCustomField(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("field_id = ?", id).Delete(&entity.CustomFieldValue{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&entity.CustomField{}, id).Error
+	})
+}
+
+func (r *customFieldRepository) GetValuesByTaskIDs(ctx context.Context, taskIds []int) ([]entity.CustomFieldValue, error) {
+	var values []entity.CustomFieldValue
+	if len(taskIds) == 0 {
+		return values, nil
+	}
+
+	err := r.db.WithContext(ctx).Where("task_id IN ?", taskIds).Order("field_id").Find(&values).Error
+	return values, err
+}
+
+func (r *customFieldRepository) StoreValues(ctx context.Context, values []entity.CustomFieldValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "field_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"text_value", "number_value", "date_value", "bool_value"}),
+	}).Create(&values).Error
+}
+
+func (r *customFieldRepository) DeleteValues(ctx context.Context, taskId int, fieldIds []int) error {
+	if len(fieldIds) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Where("task_id = ? AND field_id IN ?", taskId, fieldIds).Delete(&entity.CustomFieldValue{}).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	ReserveKey(ctx context.Context, record *entity.IdempotencyKey) (reserved bool, err error)
+	GetKey(ctx context.Context, userId int, key string) (entity.IdempotencyKey, error)
+	ReclaimKey(ctx context.Context, id int, now time.Time, lockedUntil time.Time) (reclaimed bool, err error)
+	CompleteKey(ctx context.Context, id int, statusCode int, header []string, body []byte) error
+	DeleteKey(ctx context.Context, id int) error
+	DeleteExpiredKeys(ctx context.Context, now time.Time) error
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+// ReserveKey stores the key unless the user already used it.
+func (r *idempotencyRepository) ReserveKey(ctx context.Context, record *entity.IdempotencyKey) (reserved bool, err error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *idempotencyRepository) GetKey(ctx context.Context, userId int, key string) (entity.IdempotencyKey, error) {
+	var record entity.IdempotencyKey
+	err := r.db.WithContext(ctx).Where("user_id = ? AND key = ?", userId, key).Find(&record).Error
+	return record, err
+}
+
+// ReclaimKey locks a key again whose request is still in progress after
+// its lease ran out. Only one of several concurrent callers reclaims it.
+func (r *idempotencyRepository) ReclaimKey(ctx context.Context, id int, now time.Time, lockedUntil time.Time) (reclaimed bool, err error) {
+	result := r.db.WithContext(ctx).Model(&entity.IdempotencyKey{}).
+		Where("id = ? AND status_code = 0 AND locked_until <= ?", id, now).
+		Update("locked_until", lockedUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *idempotencyRepository) CompleteKey(ctx context.Context, id int, statusCode int, header []string, body []byte) error {
+	return r.db.WithContext(ctx).Model(&entity.IdempotencyKey{ID: id}).Updates(map[string]interface{}{
+		"status_code":     statusCode,
+		"response_header": pq.StringArray(header),
+		"response_body":   body,
+	}).Error
+}
+
+func (r *idempotencyRepository) DeleteKey(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Delete(&entity.IdempotencyKey{}, id).Error
+}
+
+func (r *idempotencyRepository) DeleteExpiredKeys(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&entity.IdempotencyKey{}).Error
+}
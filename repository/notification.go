@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	StoreWatcher(ctx context.Context, watcher *entity.Watcher) error
+	DeleteWatcher(ctx context.Context, taskId, userId int) error
+	GetWatchersByTaskID(ctx context.Context, taskId int) ([]entity.Watcher, error)
+	StoreNotifications(ctx context.Context, notifications []entity.Notification) error
+	GetNotificationsByUserId(ctx context.Context, id int, unreadOnly bool) ([]entity.Notification, error)
+	CountUnread(ctx context.Context, id int) (int64, error)
+	MarkRead(ctx context.Context, id int, notificationIds []int) error
+	StoreMentions(ctx context.Context, mentions []entity.Mention) error
+	GetMentionsByTaskIDs(ctx context.Context, taskIds []int) ([]entity.Mention, error)
+	DeleteDescriptionMentions(ctx context.Context, taskId int) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (r *notificationRepository) StoreWatcher(ctx context.Context, watcher *entity.Watcher) error {
+	// watching an already watched task is a no-op
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&watcher).Error
+}
+
+func (r *notificationRepository) DeleteWatcher(ctx context.Context, taskId, userId int) error {
+	return r.db.WithContext(ctx).Where("task_id = ? AND user_id = ?", taskId, userId).Delete(&entity.Watcher{}).Error
+}
+
+func (r *notificationRepository) GetWatchersByTaskID(ctx context.Context, taskId int) ([]entity.Watcher, error) {
+	var watchers []entity.Watcher
+	err := r.db.WithContext(ctx).Where("task_id = ?", taskId).Find(&watchers).Error
+	return watchers, err
+}
+
+func (r *notificationRepository) StoreNotifications(ctx context.Context, notifications []entity.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&notifications).Error
+}
+
+func (r *notificationRepository) GetNotificationsByUserId(ctx context.Context, id int, unreadOnly bool) ([]entity.Notification, error) {
+	var notifications []entity.Notification
+
+	query := r.db.WithContext(ctx).Where("user_id = ?", id)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	err := query.Order("created_at DESC").Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, id int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", id).Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id int, notificationIds []int) error {
+	query := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", id)
+	if len(notificationIds) > 0 {
+		query = query.Where("id IN ?", notificationIds)
+	}
+
+	return query.Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepository) StoreMentions(ctx context.Context, mentions []entity.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit("Email").Create(&mentions).Error
+}
+
+func (r *notificationRepository) GetMentionsByTaskIDs(ctx context.Context, taskIds []int) ([]entity.Mention, error) {
+	var mentions []entity.Mention
+	if len(taskIds) == 0 {
+		return mentions, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&entity.Mention{}).
+		Select("mentions.*, users.email").
+		Joins("JOIN users ON users.id = mentions.user_id").
+		Where("mentions.task_id IN ?", taskIds).
+		Order("mentions.id").
+		Find(&mentions).Error
+	return mentions, err
+}
+
+func (r *notificationRepository) DeleteDescriptionMentions(ctx context.Context, taskId int) error {
+	return r.db.WithContext(ctx).Where("task_id = ? AND comment_id = 0", taskId).Delete(&entity.Mention{}).Error
+}
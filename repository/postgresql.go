@@ -33,12 +33,27 @@ func ConnectDB() error {
 		return err
 	}
 
-	conn.AutoMigrate(entity.User{}, entity.Category{}, entity.Task{})
+	conn.AutoMigrate(entity.User{}, entity.Category{}, entity.Task{}, entity.TimeEntry{}, entity.CustomField{}, entity.CustomFieldValue{}, entity.Watcher{}, entity.Notification{}, entity.Comment{}, entity.Mention{}, entity.IdempotencyKey{}, entity.Webhook{}, entity.WebhookDelivery{}, entity.CalendarFeed{})
+	err = CreateSearchIndexes(conn)
+	if err != nil {
+		return err
+	}
 	SetupDBConnection(conn)
 
 	return nil
 }
 
+// CreateSearchIndexes adds the GIN indexes backing task search. They are
+// expression indexes, so they must match the documents used in SearchTasks.
+func CreateSearchIndexes(conn *gorm.DB) error {
+	err := conn.Exec("CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (" + taskSearchDocument + ")").Error
+	if err != nil {
+		return err
+	}
+
+	return conn.Exec("CREATE INDEX IF NOT EXISTS idx_comments_search ON comments USING GIN (" + commentSearchDocument + ")").Error
+}
+
 func SetupDBConnection(DB *gorm.DB) {
 	db = DB
 }
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/snykk/kanban-app/entity"
 
@@ -11,10 +13,17 @@ import (
 type TaskRepository interface {
 	GetTasks(ctx context.Context, id int) ([]entity.Task, error)
 	StoreTask(ctx context.Context, task *entity.Task) (taskId int, err error)
+	StoreTasksWithCategories(ctx context.Context, categories []entity.Category, tasks []entity.Task, categoryOf map[int]int) error
 	GetTaskByID(ctx context.Context, id int) (entity.Task, error)
+	GetTasksByIDs(ctx context.Context, ids []int) ([]entity.Task, error)
 	GetTasksByCategoryID(ctx context.Context, catId int) ([]entity.Task, error)
+	GetTasksByCategoryIDs(ctx context.Context, catIds []int) ([]entity.Task, error)
+	GetTasksByFilter(ctx context.Context, id int, filter entity.TaskFilter) (tasks []entity.Task, total int64, err error)
+	SearchTasks(ctx context.Context, id int, filter entity.TaskSearchFilter) ([]entity.TaskSearchResult, error)
 	UpdateTask(ctx context.Context, task *entity.Task) error
-	DeleteTask(ctx context.Context, id int) error
+	PatchTask(ctx context.Context, id int, version int, fields map[string]interface{}) (newVersion int, err error)
+	DeleteTask(ctx context.Context, id int, version int) error
+	BulkTasks(ctx context.Context, userId int, ids []int, req entity.BulkTaskRequest) error
 }
 
 type taskRepository struct {
@@ -27,7 +36,7 @@ func NewTaskRepository(db *gorm.DB) TaskRepository {
 
 func (r *taskRepository) GetTasks(ctx context.Context, id int) ([]entity.Task, error) {
 	var tasks []entity.Task
-	err := r.db.WithContext(ctx).Where("user_id = ?", id).Find(&tasks).Error
+	err := r.db.WithContext(ctx).Where("user_id = ? AND archived_at IS NULL", id).Find(&tasks).Error
 	return tasks, err
 }
 
@@ -39,22 +48,260 @@ func (r *taskRepository) StoreTask(ctx context.Context, task *entity.Task) (task
 	return task.ID, nil
 }
 
+// StoreTasksWithCategories creates the categories and then the tasks in one
+// transaction, setting their ids in place. categoryOf maps the index of a
+// task to the index of its category in categories.
+func (r *taskRepository) StoreTasksWithCategories(ctx context.Context, categories []entity.Category, tasks []entity.Task, categoryOf map[int]int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(categories) > 0 {
+			err := tx.Create(&categories).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, category := range categoryOf {
+			tasks[i].CategoryID = categories[category].ID
+		}
+
+		if len(tasks) == 0 {
+			return nil
+		}
+		return tx.Create(&tasks).Error
+	})
+}
+
 func (r *taskRepository) GetTaskByID(ctx context.Context, id int) (entity.Task, error) {
 	var task entity.Task
 	err := r.db.WithContext(ctx).Find(&task, id).Error
 	return task, err
 }
 
+func (r *taskRepository) GetTasksByIDs(ctx context.Context, ids []int) ([]entity.Task, error) {
+	var tasks []entity.Task
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&tasks).Error
+	return tasks, err
+}
+
 func (r *taskRepository) GetTasksByCategoryID(ctx context.Context, catId int) ([]entity.Task, error) {
 	var task []entity.Task
 	err := r.db.WithContext(ctx).Where("category_id = ?", catId).Find(&task).Error
 	return task, err
 }
 
+// GetTasksByCategoryIDs returns the tasks on the board, archived ones are
+// left out, of all the given categories.
+func (r *taskRepository) GetTasksByCategoryIDs(ctx context.Context, catIds []int) ([]entity.Task, error) {
+	var tasks []entity.Task
+	err := r.db.WithContext(ctx).Where("category_id IN ? AND archived_at IS NULL", catIds).Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) GetTasksByFilter(ctx context.Context, id int, filter entity.TaskFilter) (tasks []entity.Task, total int64, err error) {
+	query := r.db.WithContext(ctx).Model(&entity.Task{}).Where("tasks.user_id = ?", id)
+
+	if filter.Archived {
+		query = query.Where("tasks.archived_at IS NOT NULL")
+	} else {
+		query = query.Where("tasks.archived_at IS NULL")
+	}
+
+	if filter.CategoryID != 0 {
+		query = query.Where("tasks.category_id = ?", filter.CategoryID)
+	}
+	if filter.Label != "" {
+		query = query.Where("? = ANY(tasks.labels)", filter.Label)
+	}
+	if filter.HasDueDate {
+		query = query.Where("tasks.due_date IS NOT NULL")
+	}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("(tasks.title ILIKE ? OR tasks.description ILIKE ?)", pattern, pattern)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("tasks.created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		// upper bounds are inclusive of the whole day
+		query = query.Where("tasks.created_at < ?", filter.CreatedTo.AddDate(0, 0, 1))
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		query = query.Where("tasks.updated_at >= ?", filter.UpdatedFrom)
+	}
+	if !filter.UpdatedTo.IsZero() {
+		query = query.Where("tasks.updated_at < ?", filter.UpdatedTo.AddDate(0, 0, 1))
+	}
+
+	// column names come from entity.CustomField.ValueColumn and
+	// entity.TaskSortColumns, never from input
+	field := filter.CustomField
+	if field.FieldID != 0 {
+		query = query.
+			Joins("JOIN custom_field_values AS filter_value ON filter_value.task_id = tasks.id AND filter_value.field_id = ?", field.FieldID).
+			Where("filter_value."+field.Column+" = ?", field.Value)
+	}
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	if field.SortID != 0 {
+		query = query.
+			Joins("LEFT JOIN custom_field_values AS sort_value ON sort_value.task_id = tasks.id AND sort_value.field_id = ?", field.SortID).
+			Order("sort_value." + field.SortColumn + " " + direction + " NULLS LAST")
+	} else if column, ok := entity.TaskSortColumns[filter.Sort]; ok {
+		query = query.Order(column + " " + direction)
+	}
+
+	err = query.
+		Order("tasks.id").
+		Limit(filter.PerPage).
+		Offset((filter.Page - 1) * filter.PerPage).
+		Find(&tasks).Error
+	return tasks, total, err
+}
+
+// documents indexed by CreateSearchIndexes
+const (
+	taskSearchDocument    = "to_tsvector('english', title || ' ' || description)"
+	commentSearchDocument = "to_tsvector('english', body)"
+)
+
+func (r *taskRepository) SearchTasks(ctx context.Context, id int, filter entity.TaskSearchFilter) ([]entity.TaskSearchResult, error) {
+	var results []entity.TaskSearchResult
+
+	categoryCondition := ""
+	args := []interface{}{filter.Query, id}
+	if filter.CategoryID != 0 {
+		categoryCondition = "AND tasks.category_id = ?"
+		args = append(args, filter.CategoryID)
+	}
+	args = append(args, filter.Limit)
+
+	// comments are folded into the rank and snippet but matched through
+	// their own index
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT tasks.id AS task_id, tasks.title, tasks.category_id, categories.type AS category_type,
+			ts_rank(`+taskSearchDocument+` || to_tsvector('english', coalesce(c.body, '')), q.query) AS rank,
+			ts_headline('english', tasks.title || ' ' || tasks.description || coalesce(' ' || c.body, ''), q.query,
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS snippet
+		FROM tasks
+		CROSS JOIN websearch_to_tsquery('english', ?) AS q(query)
+		JOIN categories ON categories.id = tasks.category_id
+		LEFT JOIN LATERAL (
+			SELECT string_agg(body, ' ' ORDER BY id) AS body FROM comments WHERE comments.task_id = tasks.id
+		) AS c ON true
+		WHERE tasks.user_id = ? `+categoryCondition+`
+			AND (`+taskSearchDocument+` @@ q.query
+				OR EXISTS (SELECT 1 FROM comments WHERE comments.task_id = tasks.id AND `+commentSearchDocument+` @@ q.query))
+		ORDER BY rank DESC, tasks.id
+		LIMIT ?`, args...).Scan(&results).Error
+	return results, err
+}
+
+// UpdateTask writes the non-zero fields of the task and its estimate, which
+// is written even when it is 0 so it can be cleared. A non-zero Version must
+// match the stored one and is replaced by the new version.
 func (r *taskRepository) UpdateTask(ctx context.Context, task *entity.Task) error {
-	return r.db.WithContext(ctx).Model(&task).Updates(&task).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := bumpVersion(tx, "tasks", task.ID, task.Version)
+		if err != nil {
+			return err
+		}
+
+		task.Version = version
+		err = tx.Model(&task).Omit("version").Updates(&task).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.Task{ID: task.ID}).Update("estimate", task.Estimate).Error
+	})
+}
+
+// PatchTask writes the given columns as they are, zero values included.
+func (r *taskRepository) PatchTask(ctx context.Context, id int, version int, fields map[string]interface{}) (newVersion int, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		newVersion, err = bumpVersion(tx, "tasks", id, version)
+		if err != nil || len(fields) == 0 {
+			return err
+		}
+
+		return tx.Model(&entity.Task{ID: id}).Updates(fields).Error
+	})
+	return newVersion, err
+}
+
+// DeleteTask removes the task together with the rows that only make sense
+// while the task exists.
+func (r *taskRepository) DeleteTask(ctx context.Context, id int, version int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if version != 0 {
+			_, err := bumpVersion(tx, "tasks", id, version)
+			if err != nil {
+				return err
+			}
+		}
+
+		return deleteTasks(tx, []int{id})
+	})
+}
+
+// BulkTasks applies one action to the given tasks of the user in a single
+// transaction.
+func (r *taskRepository) BulkTasks(ctx context.Context, userId int, ids []int, req entity.BulkTaskRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if req.Action == entity.BulkActionDelete {
+			return deleteTasks(tx, ids)
+		}
+
+		tasks := tx.Model(&entity.Task{}).Where("id IN ? AND user_id = ?", ids, userId)
+		updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+
+		switch req.Action {
+		case entity.BulkActionMove:
+			updates["category_id"] = req.CategoryID
+		case entity.BulkActionArchive:
+			tasks = tasks.Where("archived_at IS NULL")
+			updates["archived_at"] = time.Now()
+		case entity.BulkActionAddLabel:
+			tasks = tasks.Where("NOT (? = ANY(labels))", req.Label)
+			updates["labels"] = gorm.Expr("array_append(labels, ?)", req.Label)
+		case entity.BulkActionAssign:
+			updates["assignee_id"] = req.AssigneeID
+		}
+
+		return tasks.Updates(updates).Error
+	})
+}
+
+func deleteTasks(tx *gorm.DB, ids []int) error {
+	dependents := []interface{}{
+		&entity.CustomFieldValue{},
+		&entity.Watcher{},
+		&entity.Comment{},
+		&entity.Mention{},
+		&entity.TimeEntry{},
+		&entity.Notification{},
+	}
+
+	for _, dependent := range dependents {
+		err := tx.Where("task_id IN ?", ids).Delete(dependent).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("id IN ?", ids).Delete(&entity.Task{}).Error
 }
 
-func (r *taskRepository) DeleteTask(ctx context.Context, id int) error {
-	return r.db.Delete(&entity.Task{}, id).Error
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+)
+
+type TimeEntryRepository interface {
+	StoreTimeEntry(ctx context.Context, entry *entity.TimeEntry) (entryId int, err error)
+	GetRunningTimeEntry(ctx context.Context, userId int) (entity.TimeEntry, error)
+	UpdateTimeEntry(ctx context.Context, entry *entity.TimeEntry) error
+	GetTimeReport(ctx context.Context, ownerId int, filter entity.TimeReportFilter) ([]entity.TimeReportItem, error)
+}
+
+type timeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewTimeEntryRepository(db *gorm.DB) TimeEntryRepository {
+	return &timeEntryRepository{db}
+}
+
+func (r *timeEntryRepository) StoreTimeEntry(ctx context.Context, entry *entity.TimeEntry) (entryId int, err error) {
+	err = r.db.WithContext(ctx).Create(&entry).Error
+	if err != nil {
+		return 0, err
+	}
+	return entry.ID, nil
+}
+
+func (r *timeEntryRepository) GetRunningTimeEntry(ctx context.Context, userId int) (entity.TimeEntry, error) {
+	var entry entity.TimeEntry
+	err := r.db.WithContext(ctx).Where("user_id = ? AND ended_at IS NULL", userId).Find(&entry).Error
+	return entry, err
+}
+
+func (r *timeEntryRepository) UpdateTimeEntry(ctx context.Context, entry *entity.TimeEntry) error {
+	return r.db.WithContext(ctx).Model(&entry).Updates(&entry).Error
+}
+
+func (r *timeEntryRepository) GetTimeReport(ctx context.Context, ownerId int, filter entity.TimeReportFilter) ([]entity.TimeReportItem, error) {
+	var items []entity.TimeReportItem
+
+	query := r.db.WithContext(ctx).
+		Table("time_entries AS te").
+		Select("te.task_id, t.title AS task_title, te.user_id, to_char(te.started_at, 'YYYY-MM-DD') AS date, SUM(te.duration) AS seconds").
+		Joins("JOIN tasks AS t ON t.id = te.task_id").
+		Where("t.user_id = ? AND te.ended_at IS NOT NULL", ownerId)
+
+	if filter.TaskID != 0 {
+		query = query.Where("te.task_id = ?", filter.TaskID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("te.user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("te.started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		// the upper bound is inclusive of the whole day
+		query = query.Where("te.started_at < ?", filter.To.AddDate(0, 0, 1))
+	}
+
+	err := query.
+		Group("te.task_id, t.title, te.user_id, to_char(te.started_at, 'YYYY-MM-DD')").
+		Order("date, te.task_id, te.user_id").
+		Scan(&items).Error
+	return items, err
+}
@@ -10,7 +10,9 @@ import (
 
 type UserRepository interface {
 	GetUserByID(ctx context.Context, id int) (entity.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int) ([]entity.User, error)
 	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
+	GetUserByHandle(ctx context.Context, handle string) (entity.User, error)
 	CreateUser(ctx context.Context, user entity.User) (entity.User, error)
 	UpdateUser(ctx context.Context, user entity.User) (entity.User, error)
 	DeleteUser(ctx context.Context, id int) error
@@ -30,12 +32,29 @@ func (r *userRepository) GetUserByID(ctx context.Context, id int) (entity.User,
 	return user, err
 }
 
+func (r *userRepository) GetUsersByIDs(ctx context.Context, ids []int) ([]entity.User, error) {
+	var users []entity.User
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
 	var user entity.User
 	err := r.db.WithContext(ctx).Where("email = ?", email).Find(&user).Error
 	return user, err
 }
 
+// GetUserByHandle treats the local part of the email address as the
+// user's handle. An ambiguous handle matches nobody.
+func (r *userRepository) GetUserByHandle(ctx context.Context, handle string) (entity.User, error) {
+	var users []entity.User
+	err := r.db.WithContext(ctx).Where("split_part(email, '@', 1) = ?", handle).Limit(2).Find(&users).Error
+	if err != nil || len(users) != 1 {
+		return entity.User{}, err
+	}
+	return users[0], nil
+}
+
 func (r *userRepository) CreateUser(ctx context.Context, user entity.User) (entity.User, error) {
 	err := r.db.WithContext(ctx).Create(&user).Error
 	return user, err
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a row was changed after the caller
+// read the version it expects.
+var ErrVersionConflict = errors.New("version conflict, the resource was modified since it was read")
+
+// bumpVersion increments the version of a row and returns the new value.
+// An expected version of 0 skips the check. Run it inside the transaction
+// doing the write so the row stays locked until the write commits.
+func bumpVersion(tx *gorm.DB, table string, id int, expected int) (int, error) {
+	query := "UPDATE " + table + " SET version = version + 1 WHERE id = ?"
+	args := []interface{}{id}
+	if expected != 0 {
+		query += " AND version = ?"
+		args = append(args, expected)
+	}
+
+	var version int
+	result := tx.Raw(query+" RETURNING version", args...).Scan(&version)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 && expected != 0 {
+		return 0, ErrVersionConflict
+	}
+	return version, nil
+}
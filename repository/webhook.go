@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+
+	"gorm.io/gorm"
+)
+
+// MaxWebhookDeliveryLog is how many deliveries are listed per webhook.
+const MaxWebhookDeliveryLog = 100
+
+type WebhookRepository interface {
+	StoreWebhook(ctx context.Context, webhook *entity.Webhook) error
+	GetWebhooksByUserID(ctx context.Context, userId int) ([]entity.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int) (entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	StoreDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	GetDeliveryByID(ctx context.Context, id int) (entity.WebhookDelivery, error)
+	GetDeliveriesByWebhookID(ctx context.Context, webhookId int) ([]entity.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, stale time.Time, limit int) ([]entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db}
+}
+
+func (r *webhookRepository) StoreWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+func (r *webhookRepository) GetWebhooksByUserID(ctx context.Context, userId int) ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+	err := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) GetWebhookByID(ctx context.Context, id int) (entity.Webhook, error) {
+	var webhook entity.Webhook
+	err := r.db.WithContext(ctx).Find(&webhook, id).Error
+	return webhook, err
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("webhook_id = ?", id).Delete(&entity.WebhookDelivery{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&entity.Webhook{}, id).Error
+	})
+}
+
+func (r *webhookRepository) StoreDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+func (r *webhookRepository) GetDeliveryByID(ctx context.Context, id int) (entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	err := r.db.WithContext(ctx).Find(&delivery, id).Error
+	return delivery, err
+}
+
+func (r *webhookRepository) GetDeliveriesByWebhookID(ctx context.Context, webhookId int) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("webhook_id = ?", webhookId).Order("id DESC").Limit(MaxWebhookDeliveryLog).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDueDeliveries marks the pending deliveries due at now as in flight
+// and returns them. Deliveries left in flight since before stale, by a
+// process that died mid-attempt, are claimed again. SKIP LOCKED keeps
+// concurrent workers from claiming the same rows.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, stale time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := r.db.WithContext(ctx).Raw(`UPDATE webhook_deliveries SET status = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE (status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at < ?)
+			ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED
+		) RETURNING *`,
+		entity.DeliveryInFlight, now, entity.DeliveryPending, now, entity.DeliveryInFlight, stale, limit).Scan(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Save(delivery).Error
+}
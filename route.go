@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/snykk/kanban-app/utils"
+)
+
+// Router dispatches the resource-style API routes by path and method.
+// Segments written as {name} match a numeric id, which is passed on as the
+// query parameter of the same name so the v1 handlers can be reused.
+type Router struct {
+	routes []*resourceRoute
+}
+
+type resourceRoute struct {
+	segments []string
+	handlers map[string]http.Handler
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+func (rt *Router) Handle(method string, pattern string, handler http.Handler, opt ...string) {
+	if len(opt) > 0 {
+		fmt.Printf("[%s]: %s %v \n", method, pattern, opt)
+	} else {
+		fmt.Printf("[%s]: %s \n", method, pattern)
+	}
+	registerRoute(method, pattern, opt)
+
+	segments := splitPath(pattern)
+	for _, route := range rt.routes {
+		if strings.Join(route.segments, "/") == strings.Join(segments, "/") {
+			route.handlers[method] = handler
+			return
+		}
+	}
+
+	rt.routes = append(rt.routes, &resourceRoute{segments, map[string]http.Handler{method: handler}})
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	segments := splitPath(r.URL.Path)
+	for _, route := range rt.routes {
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+
+		handler, ok := route.handlers[r.Method]
+		if !ok {
+			w.Header().Set("Allow", route.allow())
+			utils.WriteError(w, http.StatusMethodNotAllowed, "method is not allowed!")
+			return
+		}
+
+		r = r.Clone(r.Context())
+		query := r.URL.Query()
+		for name, value := range params {
+			query.Set(name, value)
+		}
+		r.URL.RawQuery = query.Encode()
+
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	utils.WriteError(w, http.StatusNotFound, "resource not found")
+}
+
+func (route *resourceRoute) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(route.segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range route.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if !isNumeric(segments[i]) {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (route *resourceRoute) allow() string {
+	methods := make([]string, 0, len(route.handlers))
+	for method := range route.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return strings.Join(methods, ", ")
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
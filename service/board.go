@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+type BoardService interface {
+	ExportBoard(ctx context.Context, userId int) (entity.BoardExport, error)
+	ImportBoard(ctx context.Context, userId int, export entity.BoardExport) (entity.BoardImportResult, error)
+	ImportTrello(ctx context.Context, userId int, board entity.TrelloBoard, dryRun bool) (entity.TrelloImportReport, error)
+}
+
+type boardService struct {
+	boardRepo repository.BoardRepository
+}
+
+func NewBoardService(boardRepo repository.BoardRepository) BoardService {
+	return &boardService{boardRepo}
+}
+
+func (s *boardService) ExportBoard(ctx context.Context, userId int) (entity.BoardExport, error) {
+	board, err := s.boardRepo.GetBoardSnapshot(ctx, userId)
+	if err != nil {
+		return entity.BoardExport{}, err
+	}
+
+	export := entity.BoardExport{
+		Version:      entity.BoardExportVersion,
+		ExportedAt:   time.Now().UTC(),
+		Categories:   []entity.ExportCategory{},
+		CustomFields: []entity.ExportCustomField{},
+		Tasks:        []entity.ExportTask{},
+	}
+
+	for _, category := range board.Categories {
+		export.Categories = append(export.Categories, entity.ExportCategory{ID: category.ID, Type: category.Type})
+	}
+
+	fieldById := make(map[int]entity.CustomField, len(board.CustomFields))
+	for _, field := range board.CustomFields {
+		fieldById[field.ID] = field
+		export.CustomFields = append(export.CustomFields, entity.ExportCustomField{ID: field.ID, Name: field.Name, Type: field.Type, Options: field.Options})
+	}
+
+	taskIndex := make(map[int]int, len(board.Tasks))
+	for _, task := range board.Tasks {
+		labels := []string(task.Labels)
+		if labels == nil {
+			labels = []string{}
+		}
+
+		taskIndex[task.ID] = len(export.Tasks)
+		export.Tasks = append(export.Tasks, entity.ExportTask{
+			ID:          task.ID,
+			CategoryID:  task.CategoryID,
+			Title:       task.Title,
+			Description: task.Description,
+			Estimate:    task.Estimate,
+			Labels:      labels,
+			DueDate:     task.DueDate,
+			ArchivedAt:  task.ArchivedAt,
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+		})
+	}
+
+	for _, value := range board.Values {
+		field, ok := fieldById[value.FieldID]
+		if !ok {
+			continue
+		}
+
+		value.SetDisplay(field)
+		task := &export.Tasks[taskIndex[value.TaskID]]
+		task.CustomFields = append(task.CustomFields, entity.ExportFieldValue{FieldID: value.FieldID, Value: value.Value})
+	}
+
+	for _, comment := range board.Comments {
+		task := &export.Tasks[taskIndex[comment.TaskID]]
+		task.Comments = append(task.Comments, entity.ExportComment{Body: comment.Body, CreatedAt: comment.CreatedAt})
+	}
+
+	for _, entry := range board.TimeEntries {
+		task := &export.Tasks[taskIndex[entry.TaskID]]
+		task.TimeEntries = append(task.TimeEntries, entity.ExportTimeEntry{StartedAt: entry.StartedAt, EndedAt: *entry.EndedAt, Duration: entry.Duration, Note: entry.Note})
+	}
+
+	return export, nil
+}
+
+// ImportBoard adds the exported board to the user's board. The whole file
+// is checked first and nothing is created when any part of it is invalid.
+func (s *boardService) ImportBoard(ctx context.Context, userId int, export entity.BoardExport) (entity.BoardImportResult, error) {
+	board, fields := snapshotOf(export)
+	if len(fields) > 0 {
+		return entity.BoardImportResult{}, NewValidationError("invalid board export", fields)
+	}
+
+	return s.boardRepo.ImportBoard(ctx, userId, board)
+}
+
+// snapshotOf checks an export and converts it to the stored form, keeping
+// the ids of the file. It reports every invalid field it finds.
+func snapshotOf(export entity.BoardExport) (entity.BoardSnapshot, []entity.FieldError) {
+	var board entity.BoardSnapshot
+	var fields []entity.FieldError
+	invalid := func(field string, format string, args ...interface{}) {
+		fields = append(fields, entity.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if export.Version != entity.BoardExportVersion {
+		invalid("version", "must be %d", entity.BoardExportVersion)
+		return board, fields
+	}
+
+	categoryIds := map[int]bool{}
+	for i, category := range export.Categories {
+		path := fmt.Sprintf("categories[%d]", i)
+		if category.ID == 0 || categoryIds[category.ID] {
+			invalid(path+".id", "must be a unique non-zero id")
+		}
+		if strings.TrimSpace(category.Type) == "" || len(category.Type) > 255 {
+			invalid(path+".type", "must be 1 to 255 characters")
+		}
+
+		categoryIds[category.ID] = true
+		board.Categories = append(board.Categories, entity.Category{ID: category.ID, Type: category.Type})
+	}
+
+	fieldById := map[int]entity.CustomField{}
+	for i, exported := range export.CustomFields {
+		path := fmt.Sprintf("custom_fields[%d]", i)
+		if _, ok := fieldById[exported.ID]; ok || exported.ID == 0 {
+			invalid(path+".id", "must be a unique non-zero id")
+		}
+		if strings.TrimSpace(exported.Name) == "" || len(exported.Name) > 255 {
+			invalid(path+".name", "must be 1 to 255 characters")
+		}
+		if !entity.IsValidCustomFieldType(exported.Type) {
+			invalid(path+".type", "unknown type %q", exported.Type)
+		}
+		if exported.Type == entity.CustomFieldSelect && len(exported.Options) == 0 {
+			invalid(path+".options", "select field needs options")
+		}
+
+		field := entity.CustomField{ID: exported.ID, Name: exported.Name, Type: exported.Type}
+		if exported.Type == entity.CustomFieldSelect {
+			field.Options = exported.Options
+		}
+		fieldById[field.ID] = field
+		board.CustomFields = append(board.CustomFields, field)
+	}
+
+	taskIds := map[int]bool{}
+	for i, exported := range export.Tasks {
+		path := fmt.Sprintf("tasks[%d]", i)
+		if exported.ID == 0 || taskIds[exported.ID] {
+			invalid(path+".id", "must be a unique non-zero id")
+		}
+		if !categoryIds[exported.CategoryID] {
+			invalid(path+".category_id", "must be the id of a category in the export")
+		}
+		if strings.TrimSpace(exported.Title) == "" || len(exported.Title) > 255 {
+			invalid(path+".title", "must be 1 to 255 characters")
+		}
+		if !entity.IsValidEstimate(exported.Estimate) {
+			invalid(path+".estimate", "must be 0 to %d", entity.MaxTaskEstimate)
+		}
+		for j, label := range exported.Labels {
+			if strings.TrimSpace(label) == "" || len(label) > entity.MaxLabelLength {
+				invalid(fmt.Sprintf("%s.labels[%d]", path, j), "must be 1 to %d characters", entity.MaxLabelLength)
+			}
+		}
+
+		taskIds[exported.ID] = true
+		board.Tasks = append(board.Tasks, entity.Task{
+			ID:          exported.ID,
+			CategoryID:  exported.CategoryID,
+			Title:       exported.Title,
+			Description: exported.Description,
+			Estimate:    exported.Estimate,
+			Labels:      exported.Labels,
+			DueDate:     exported.DueDate,
+			ArchivedAt:  exported.ArchivedAt,
+			CreatedAt:   exported.CreatedAt,
+			UpdatedAt:   exported.UpdatedAt,
+		})
+
+		for j, exportedValue := range exported.CustomFields {
+			valuePath := fmt.Sprintf("%s.custom_fields[%d]", path, j)
+			field, ok := fieldById[exportedValue.FieldID]
+			if !ok {
+				invalid(valuePath+".field_id", "must be the id of a custom field in the export")
+				continue
+			}
+			if exportedValue.Value == nil {
+				continue
+			}
+
+			value, err := parseCustomFieldValue(field, exportedValue.Value)
+			if err != nil {
+				invalid(valuePath+".value", "%s", strings.TrimPrefix(err.Error(), ErrInvalidCustomField.Error()+": "))
+				continue
+			}
+			value.TaskID = exported.ID
+			board.Values = append(board.Values, value)
+		}
+
+		for j, comment := range exported.Comments {
+			if strings.TrimSpace(comment.Body) == "" {
+				invalid(fmt.Sprintf("%s.comments[%d].body", path, j), "is required")
+			}
+			board.Comments = append(board.Comments, entity.Comment{TaskID: exported.ID, Body: comment.Body, CreatedAt: comment.CreatedAt})
+		}
+
+		for j, entry := range exported.TimeEntries {
+			if entry.StartedAt.IsZero() || !entry.EndedAt.After(entry.StartedAt) || len(entry.Note) > 255 {
+				invalid(fmt.Sprintf("%s.time_entries[%d]", path, j), "must end after it starts, with a note of at most 255 characters")
+			}
+
+			endedAt := entry.EndedAt
+			board.TimeEntries = append(board.TimeEntries, entity.TimeEntry{
+				TaskID:    exported.ID,
+				StartedAt: entry.StartedAt,
+				EndedAt:   &endedAt,
+				Duration:  int64(entry.EndedAt.Sub(entry.StartedAt).Seconds()),
+				Note:      entry.Note,
+			})
+		}
+	}
+
+	return board, fields
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var (
+	ErrCalendarFeedNotFound  = newError(KindNotFound, "calendar_feed_not_found", "calendar feed not found")
+	ErrInvalidCalendarFilter = newError(KindInvalid, "invalid_calendar_filter", "invalid calendar filter")
+)
+
+type CalendarService interface {
+	GetFeed(ctx context.Context, userId int) (entity.CalendarFeed, error)
+	RegenerateToken(ctx context.Context, userId int) (entity.CalendarFeed, error)
+	DeleteFeed(ctx context.Context, userId int) error
+	RenderFeed(ctx context.Context, token string, filter entity.CalendarFilter) (string, error)
+}
+
+type calendarService struct {
+	calendarRepo repository.CalendarRepository
+	taskRepo     repository.TaskRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewCalendarService(calendarRepo repository.CalendarRepository, taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository) CalendarService {
+	return &calendarService{calendarRepo, taskRepo, categoryRepo}
+}
+
+func (s *calendarService) GetFeed(ctx context.Context, userId int) (entity.CalendarFeed, error) {
+	feed, err := s.calendarRepo.GetFeedByUserID(ctx, userId)
+	if err != nil {
+		return entity.CalendarFeed{}, err
+	}
+
+	if feed.ID == 0 {
+		return entity.CalendarFeed{}, ErrCalendarFeedNotFound
+	}
+	return feed, nil
+}
+
+// RegenerateToken creates the user's feed, or gives it a new token so that
+// the previous URL stops working.
+func (s *calendarService) RegenerateToken(ctx context.Context, userId int) (entity.CalendarFeed, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return entity.CalendarFeed{}, err
+	}
+
+	feed := entity.CalendarFeed{UserID: userId, Token: hex.EncodeToString(secret)}
+	err = s.calendarRepo.SaveFeed(ctx, &feed)
+	if err != nil {
+		return entity.CalendarFeed{}, err
+	}
+	return feed, nil
+}
+
+func (s *calendarService) DeleteFeed(ctx context.Context, userId int) error {
+	_, err := s.GetFeed(ctx, userId)
+	if err != nil {
+		return err
+	}
+	return s.calendarRepo.DeleteFeed(ctx, userId)
+}
+
+// RenderFeed renders the active tasks with a due date of the token's owner
+// as an iCalendar (RFC 5545) document, one all-day event or to-do per task.
+// A task keeps its UID for its whole life and its version is the SEQUENCE,
+// so clients update a moved or edited task in place and drop a deleted one
+// on their next refresh.
+func (s *calendarService) RenderFeed(ctx context.Context, token string, filter entity.CalendarFilter) (string, error) {
+	switch filter.Type {
+	case "":
+		filter.Type = entity.CalendarEvents
+	case entity.CalendarEvents, entity.CalendarTodos:
+	default:
+		return "", fmt.Errorf("%w: type must be %s or %s", ErrInvalidCalendarFilter, entity.CalendarEvents, entity.CalendarTodos)
+	}
+
+	if token == "" {
+		return "", ErrCalendarFeedNotFound
+	}
+	feed, err := s.calendarRepo.GetFeedByToken(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if feed.ID == 0 {
+		return "", ErrCalendarFeedNotFound
+	}
+	if filter.BoardID != 0 && filter.BoardID != feed.UserID {
+		return "", ErrBoardNotFound
+	}
+
+	categories, err := s.categoryRepo.GetCategoriesByUserId(ctx, feed.UserID)
+	if err != nil {
+		return "", err
+	}
+	categoryNames := make(map[int]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Type
+	}
+
+	tasks, err := getAllTasks(ctx, s.taskRepo, feed.UserID, entity.TaskFilter{
+		CategoryID: filter.CategoryID,
+		Label:      filter.Label,
+		HasDueDate: true,
+		Sort:       "due_date",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//kanban-app//task due dates//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Kanban tasks",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		"X-PUBLISHED-TTL:PT1H",
+	}
+
+	for _, task := range tasks {
+		component := "VEVENT"
+		if filter.Type == entity.CalendarTodos {
+			component = "VTODO"
+		}
+
+		due := task.DueDate.Format("20060102")
+		stamp := task.UpdatedAt.UTC().Format("20060102T150405Z")
+		lines = append(lines,
+			"BEGIN:"+component,
+			fmt.Sprintf("UID:task-%d@kanban-app", task.ID),
+			"DTSTAMP:"+stamp,
+			"LAST-MODIFIED:"+stamp,
+			fmt.Sprintf("SEQUENCE:%d", task.Version),
+			"SUMMARY:"+escapeICalText(task.Title),
+		)
+
+		if component == "VEVENT" {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+due,
+				"DTEND;VALUE=DATE:"+task.DueDate.AddDate(0, 0, 1).Format("20060102"),
+				"TRANSP:TRANSPARENT",
+			)
+		} else {
+			status := "NEEDS-ACTION"
+			if strings.EqualFold(categoryNames[task.CategoryID], entity.DoneCategory) {
+				status = "COMPLETED"
+			}
+			lines = append(lines, "DUE;VALUE=DATE:"+due, "STATUS:"+status)
+		}
+
+		if task.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeICalText(task.Description))
+		}
+
+		var tags []string
+		if name := categoryNames[task.CategoryID]; name != "" {
+			tags = append(tags, escapeICalText(name))
+		}
+		for _, label := range task.Labels {
+			tags = append(tags, escapeICalText(label))
+		}
+		if len(tags) > 0 {
+			lines = append(lines, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+
+		lines = append(lines, "END:"+component)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var ics strings.Builder
+	for _, line := range lines {
+		ics.WriteString(foldICalLine(line))
+		ics.WriteString("\r\n")
+	}
+	return ics.String(), nil
+}
+
+var iCalTextEscaper = strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n", "\r", "")
+
+func escapeICalText(s string) string {
+	return iCalTextEscaper.Replace(s)
+}
+
+// foldICalLine splits a content line longer than 75 octets into lines
+// continued by a leading space, without splitting a character.
+func foldICalLine(line string) string {
+	const limit = 75
+
+	var folded strings.Builder
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space counts towards the next line
+		width = limit - 1
+	}
+	folded.WriteString(line)
+	return folded.String()
+}
@@ -7,22 +7,31 @@ import (
 	"github.com/snykk/kanban-app/repository"
 )
 
+var (
+	ErrCategoryNotFound = newError(KindNotFound, "category_not_found", "category not found")
+	ErrBoardNotFound    = newError(KindNotFound, "board_not_found", "board not found")
+)
+
 type CategoryService interface {
 	GetCategories(ctx context.Context, id int) ([]entity.Category, error)
 	StoreCategory(ctx context.Context, category *entity.Category) (entity.Category, error)
 	GetCategoryByID(ctx context.Context, id int) (entity.Category, error)
 	UpdateCategory(ctx context.Context, category *entity.Category) (entity.Category, error)
-	DeleteCategory(ctx context.Context, id int) error
+	PatchCategory(ctx context.Context, userId int, id int, patch entity.CategoryPatch) (entity.Category, error)
+	DeleteCategory(ctx context.Context, userId int, id int, version int) error
 	GetCategoriesWithTasks(ctx context.Context, id int) ([]entity.CategoryData, error)
 }
 
 type categoryService struct {
-	catRepo  repository.CategoryRepository
-	taskRepo repository.TaskRepository
+	catRepo          repository.CategoryRepository
+	taskRepo         repository.TaskRepository
+	customFieldRepo  repository.CustomFieldRepository
+	notificationRepo repository.NotificationRepository
+	eventBus         EventBus
 }
 
-func NewCategoryService(catRepo repository.CategoryRepository, taskRepo repository.TaskRepository) CategoryService {
-	return &categoryService{catRepo, taskRepo}
+func NewCategoryService(catRepo repository.CategoryRepository, taskRepo repository.TaskRepository, customFieldRepo repository.CustomFieldRepository, notificationRepo repository.NotificationRepository, eventBus EventBus) CategoryService {
+	return &categoryService{catRepo, taskRepo, customFieldRepo, notificationRepo, eventBus}
 }
 
 func (s *categoryService) GetCategories(ctx context.Context, id int) ([]entity.Category, error) {
@@ -34,6 +43,8 @@ func (s *categoryService) StoreCategory(ctx context.Context, category *entity.Ca
 	if err != nil {
 		return entity.Category{}, err
 	}
+
+	s.eventBus.Publish(categoryEvent(entity.EventCategoryCreated, *category))
 	return *category, nil
 }
 
@@ -46,25 +57,59 @@ func (s *categoryService) UpdateCategory(ctx context.Context, category *entity.C
 	if err != nil {
 		return entity.Category{}, err
 	}
+
+	if category.UserID != 0 {
+		s.eventBus.Publish(categoryEvent(entity.EventCategoryUpdated, *category))
+	}
 	return *category, nil
 }
 
-func (s *categoryService) DeleteCategory(ctx context.Context, id int) error {
-	tasks, err := s.taskRepo.GetTasksByCategoryID(ctx, id)
+func (s *categoryService) PatchCategory(ctx context.Context, userId int, id int, patch entity.CategoryPatch) (entity.Category, error) {
+	category, err := s.catRepo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	if category.ID == 0 || category.UserID != userId {
+		return entity.Category{}, ErrCategoryNotFound
+	}
+
+	if patch.Version != 0 && patch.Version != category.Version {
+		return entity.Category{}, ErrVersionConflict
+	}
+
+	if patch.Type != nil {
+		category.Type = *patch.Type
+	}
+	category.Version = patch.Version
+
+	return s.UpdateCategory(ctx, &category)
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, userId int, id int, version int) error {
+	category, err := s.catRepo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if category.ID == 0 || category.UserID != userId {
+		return ErrCategoryNotFound
+	}
+
+	tasks, err := s.catRepo.DeleteCategory(ctx, id, version)
 	if err != nil {
 		return err
 	}
 
-	if len(tasks) > 0 {
-		for _, task := range tasks {
-			err := s.taskRepo.DeleteTask(ctx, task.ID)
-			if err != nil {
-				return err
-			}
-		}
+	for _, task := range tasks {
+		s.eventBus.Publish(entity.BoardEvent{Type: entity.EventTaskDeleted, BoardID: task.UserID, ActorID: userId, TaskID: task.ID, CategoryID: task.CategoryID})
 	}
+	s.eventBus.Publish(entity.BoardEvent{Type: entity.EventCategoryDeleted, BoardID: category.UserID, ActorID: userId, CategoryID: category.ID})
+	return nil
+}
 
-	return s.catRepo.DeleteCategory(ctx, id)
+func categoryEvent(eventType string, category entity.Category) entity.BoardEvent {
+	return entity.BoardEvent{Type: eventType, BoardID: category.UserID, ActorID: category.UserID, CategoryID: category.ID, Category: &category}
 }
 
 func (s *categoryService) GetCategoriesWithTasks(ctx context.Context, id int) ([]entity.CategoryData, error) {
@@ -78,6 +123,16 @@ func (s *categoryService) GetCategoriesWithTasks(ctx context.Context, id int) ([
 		return nil, err
 	}
 
+	err = attachCustomFields(ctx, s.customFieldRepo, id, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachMentions(ctx, s.notificationRepo, tasks)
+	if err != nil {
+		return nil, err
+	}
+
 	var categoryData = entity.DataToCategoryData(categories, tasks)
 	return categoryData, nil
 }
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+// collabQueueSize is how many messages a connection may fall behind before
+// it is dropped.
+const collabQueueSize = 32
+
+var ErrTaskLocked = newError(KindConflict, "task_locked", "task is being edited on another connection")
+
+// CollabHub tracks who is connected to a board and which task each
+// connection is editing, and relays the board events to them. Locks are
+// soft: they are advertised to the other connections, writes are not
+// blocked by them.
+type CollabHub interface {
+	Join(boardId int, user entity.User) *CollabClient
+	Leave(client *CollabClient)
+	Edit(ctx context.Context, client *CollabClient, taskId int) error
+	StopEdit(client *CollabClient)
+	Send(client *CollabClient, message entity.CollabMessage)
+}
+
+// CollabClient is one connection to a board. Its messages are read from
+// Messages, which is closed once the client left or fell too far behind.
+type CollabClient struct {
+	boardId  int
+	presence entity.PresenceClient
+	send     chan entity.CollabMessage
+	closed   bool
+}
+
+func (c *CollabClient) ID() int64 {
+	return c.presence.ClientID
+}
+
+func (c *CollabClient) Messages() <-chan entity.CollabMessage {
+	return c.send
+}
+
+type collabBoard struct {
+	clients     map[int64]*CollabClient
+	unsubscribe func()
+}
+
+type collabHub struct {
+	mu       sync.Mutex
+	lastID   int64
+	boards   map[int]*collabBoard
+	eventBus EventBus
+	taskRepo repository.TaskRepository
+}
+
+func NewCollabHub(eventBus EventBus, taskRepo repository.TaskRepository) CollabHub {
+	return &collabHub{boards: map[int]*collabBoard{}, eventBus: eventBus, taskRepo: taskRepo}
+}
+
+func (h *collabHub) Join(boardId int, user entity.User) *CollabClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	client := &CollabClient{
+		boardId: boardId,
+		presence: entity.PresenceClient{
+			ClientID: h.lastID,
+			UserID:   user.ID,
+			Fullname: user.Fullname,
+			JoinedAt: time.Now(),
+		},
+		send: make(chan entity.CollabMessage, collabQueueSize),
+	}
+
+	board, ok := h.boards[boardId]
+	if !ok {
+		events, unsubscribe := h.eventBus.Subscribe(boardId)
+		board = &collabBoard{clients: map[int64]*CollabClient{}, unsubscribe: unsubscribe}
+		h.boards[boardId] = board
+		go h.relay(boardId, board, events)
+	}
+	board.clients[client.ID()] = client
+
+	h.broadcastPresence(board)
+	return client
+}
+
+func (h *collabHub) Leave(client *CollabClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(client)
+}
+
+func (h *collabHub) Edit(ctx context.Context, client *CollabClient, taskId int) error {
+	task, err := h.taskRepo.GetTaskByID(ctx, taskId)
+	if err != nil {
+		return err
+	}
+
+	if task.ID == 0 || task.UserID != client.boardId {
+		return ErrTaskNotFound
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	board, ok := h.boards[client.boardId]
+	if !ok || client.closed {
+		return nil
+	}
+
+	for _, other := range board.clients {
+		if other != client && other.presence.EditingTaskID == taskId {
+			return ErrTaskLocked
+		}
+	}
+
+	if client.presence.EditingTaskID != taskId {
+		client.presence.EditingTaskID = taskId
+		h.broadcastPresence(board)
+	}
+	return nil
+}
+
+func (h *collabHub) StopEdit(client *CollabClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	board, ok := h.boards[client.boardId]
+	if !ok || client.closed || client.presence.EditingTaskID == 0 {
+		return
+	}
+
+	client.presence.EditingTaskID = 0
+	h.broadcastPresence(board)
+}
+
+func (h *collabHub) Send(client *CollabClient, message entity.CollabMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.deliver(client, message)
+}
+
+// relay forwards the events of a board to its clients. When the event bus
+// gives up on the board the clients are dropped, so they reconnect and
+// reload instead of silently missing events.
+func (h *collabHub) relay(boardId int, board *collabBoard, events <-chan entity.BoardEvent) {
+	for event := range events {
+		event := event
+
+		h.mu.Lock()
+		if event.Type == entity.EventTaskDeleted {
+			released := false
+			for _, client := range board.clients {
+				if client.presence.EditingTaskID == event.TaskID {
+					client.presence.EditingTaskID = 0
+					released = true
+				}
+			}
+			if released {
+				h.broadcastPresence(board)
+			}
+		}
+
+		for _, client := range board.clients {
+			h.deliver(client, entity.CollabMessage{Type: entity.CollabEvent, Event: &event})
+		}
+		h.mu.Unlock()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.boards[boardId] == board {
+		for _, client := range board.clients {
+			h.remove(client)
+		}
+	}
+}
+
+// The helpers below must be called with mu held.
+
+func (h *collabHub) deliver(client *CollabClient, message entity.CollabMessage) {
+	if client.closed {
+		return
+	}
+
+	select {
+	case client.send <- message:
+	default:
+		// never block the board on a slow connection
+		h.remove(client)
+	}
+}
+
+func (h *collabHub) remove(client *CollabClient) {
+	if client.closed {
+		return
+	}
+
+	client.closed = true
+	close(client.send)
+
+	board, ok := h.boards[client.boardId]
+	if !ok {
+		return
+	}
+
+	delete(board.clients, client.ID())
+	if len(board.clients) == 0 {
+		delete(h.boards, client.boardId)
+		board.unsubscribe()
+		return
+	}
+
+	h.broadcastPresence(board)
+}
+
+func (h *collabHub) broadcastPresence(board *collabBoard) {
+	presence := make([]entity.PresenceClient, 0, len(board.clients))
+	for _, client := range board.clients {
+		presence = append(presence, client.presence)
+	}
+
+	sort.Slice(presence, func(i, j int) bool {
+		return presence[i].ClientID < presence[j].ClientID
+	})
+
+	for _, client := range board.clients {
+		h.deliver(client, entity.CollabMessage{Type: entity.CollabPresence, ClientID: client.ID(), Presence: presence})
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+type CommentService interface {
+	GetComments(ctx context.Context, userId, taskId int) ([]entity.Comment, error)
+	StoreComment(ctx context.Context, comment *entity.Comment) (entity.Comment, error)
+}
+
+type commentService struct {
+	commentRepo      repository.CommentRepository
+	taskRepo         repository.TaskRepository
+	userRepo         repository.UserRepository
+	notificationRepo repository.NotificationRepository
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository, notificationRepo repository.NotificationRepository) CommentService {
+	return &commentService{commentRepo, taskRepo, userRepo, notificationRepo}
+}
+
+func (s *commentService) GetComments(ctx context.Context, userId, taskId int) ([]entity.Comment, error) {
+	task, err := s.taskRepo.GetTaskByID(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.ID == 0 || task.UserID != userId {
+		return nil, ErrTaskNotFound
+	}
+
+	comments, err := s.commentRepo.GetCommentsByTaskID(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.notificationRepo.GetMentionsByTaskIDs(ctx, []int{taskId})
+	if err != nil {
+		return nil, err
+	}
+
+	mentionsByComment := make(map[int][]entity.Mention)
+	for _, mention := range mentions {
+		if mention.CommentID != 0 {
+			mentionsByComment[mention.CommentID] = append(mentionsByComment[mention.CommentID], mention)
+		}
+	}
+
+	for i := range comments {
+		comments[i].Mentions = mentionsByComment[comments[i].ID]
+	}
+
+	return comments, nil
+}
+
+func (s *commentService) StoreComment(ctx context.Context, comment *entity.Comment) (entity.Comment, error) {
+	task, err := s.taskRepo.GetTaskByID(ctx, comment.TaskID)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	if task.ID == 0 || task.UserID != comment.UserID {
+		return entity.Comment{}, ErrTaskNotFound
+	}
+
+	comment.CreatedAt = time.Now()
+	_, err = s.commentRepo.StoreComment(ctx, comment)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	err = recordMentions(ctx, s.userRepo, s.notificationRepo, comment.UserID, task, comment.ID, comment.Body, map[int]bool{})
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	notifyWatchers(ctx, s.notificationRepo, comment.UserID, task, entity.NotificationCommented)
+
+	return *comment, nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var (
+	ErrCustomFieldNotFound = newError(KindNotFound, "custom_field_not_found", "custom field not found")
+	ErrInvalidCustomField  = newError(KindInvalid, "invalid_custom_field", "invalid custom field")
+)
+
+type CustomFieldService interface {
+	GetCustomFields(ctx context.Context, id int) ([]entity.CustomField, error)
+	StoreCustomField(ctx context.Context, field *entity.CustomField) (entity.CustomField, error)
+	DeleteCustomField(ctx context.Context, userId, id int) error
+}
+
+type customFieldService struct {
+	customFieldRepo repository.CustomFieldRepository
+}
+
+func NewCustomFieldService(customFieldRepo repository.CustomFieldRepository) CustomFieldService {
+	return &customFieldService{customFieldRepo}
+}
+
+func (s *customFieldService) GetCustomFields(ctx context.Context, id int) ([]entity.CustomField, error) {
+	return s.customFieldRepo.GetCustomFieldsByUserId(ctx, id)
+}
+
+func (s *customFieldService) StoreCustomField(ctx context.Context, field *entity.CustomField) (entity.CustomField, error) {
+	if !entity.IsValidCustomFieldType(field.Type) {
+		return entity.CustomField{}, fmt.Errorf("%w: unknown type %q", ErrInvalidCustomField, field.Type)
+	}
+
+	if field.Type == entity.CustomFieldSelect && len(field.Options) == 0 {
+		return entity.CustomField{}, fmt.Errorf("%w: select field needs options", ErrInvalidCustomField)
+	}
+
+	if field.Type != entity.CustomFieldSelect {
+		field.Options = nil
+	}
+
+	_, err := s.customFieldRepo.StoreCustomField(ctx, field)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+	return *field, nil
+}
+
+func (s *customFieldService) DeleteCustomField(ctx context.Context, userId, id int) error {
+	field, err := s.customFieldRepo.GetCustomFieldByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if field.ID == 0 || field.UserID != userId {
+		return ErrCustomFieldNotFound
+	}
+
+	return s.customFieldRepo.DeleteCustomField(ctx, id)
+}
+
+// attachCustomFields loads the custom field values of tasks owned by userId
+// and fills them in for display.
+func attachCustomFields(ctx context.Context, customFieldRepo repository.CustomFieldRepository, userId int, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	fields, err := customFieldRepo.GetCustomFieldsByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	fieldById := make(map[int]entity.CustomField, len(fields))
+	for _, field := range fields {
+		fieldById[field.ID] = field
+	}
+
+	taskIds := make([]int, len(tasks))
+	for i, task := range tasks {
+		taskIds[i] = task.ID
+	}
+
+	values, err := customFieldRepo.GetValuesByTaskIDs(ctx, taskIds)
+	if err != nil {
+		return err
+	}
+
+	valuesByTask := make(map[int][]entity.CustomFieldValue)
+	for _, value := range values {
+		field, ok := fieldById[value.FieldID]
+		if !ok {
+			continue
+		}
+
+		value.SetDisplay(field)
+		valuesByTask[value.TaskID] = append(valuesByTask[value.TaskID], value)
+	}
+
+	for i := range tasks {
+		tasks[i].CustomFields = valuesByTask[tasks[i].ID]
+	}
+
+	return nil
+}
+
+// buildCustomFieldValues validates submitted values against the board's
+// field definitions. A null value clears the field, so its id is returned
+// in cleared instead.
+func buildCustomFieldValues(ctx context.Context, customFieldRepo repository.CustomFieldRepository, userId int, inputs []entity.CustomFieldValue) (values []entity.CustomFieldValue, cleared []int, err error) {
+	if len(inputs) == 0 {
+		return nil, nil, nil
+	}
+
+	fields, err := customFieldRepo.GetCustomFieldsByUserId(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fieldById := make(map[int]entity.CustomField, len(fields))
+	for _, field := range fields {
+		fieldById[field.ID] = field
+	}
+
+	for _, input := range inputs {
+		field, ok := fieldById[input.FieldID]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: field %d does not exist on this board", ErrInvalidCustomField, input.FieldID)
+		}
+
+		if input.Value == nil {
+			cleared = append(cleared, field.ID)
+			continue
+		}
+
+		value, err := parseCustomFieldValue(field, input.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, cleared, nil
+}
+
+func saveCustomFieldValues(ctx context.Context, customFieldRepo repository.CustomFieldRepository, taskId int, values []entity.CustomFieldValue, cleared []int) error {
+	for i := range values {
+		values[i].TaskID = taskId
+	}
+
+	if err := customFieldRepo.DeleteValues(ctx, taskId, cleared); err != nil {
+		return err
+	}
+
+	return customFieldRepo.StoreValues(ctx, values)
+}
+
+// resolveCustomFieldFilter turns the field ids and raw filter value from the
+// task listing into typed columns and values.
+func resolveCustomFieldFilter(ctx context.Context, customFieldRepo repository.CustomFieldRepository, userId int, filter entity.CustomFieldFilter) (entity.CustomFieldFilter, error) {
+	fields, err := customFieldRepo.GetCustomFieldsByUserId(ctx, userId)
+	if err != nil {
+		return filter, err
+	}
+
+	fieldById := make(map[int]entity.CustomField, len(fields))
+	for _, field := range fields {
+		fieldById[field.ID] = field
+	}
+
+	if filter.FieldID != 0 {
+		field, ok := fieldById[filter.FieldID]
+		if !ok {
+			return filter, ErrCustomFieldNotFound
+		}
+
+		value, err := parseCustomFieldValue(field, filter.Value)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.Column = field.ValueColumn()
+		switch {
+		case value.NumberValue != nil:
+			filter.Value = *value.NumberValue
+		case value.DateValue != nil:
+			filter.Value = *value.DateValue
+		case value.BoolValue != nil:
+			filter.Value = *value.BoolValue
+		default:
+			filter.Value = *value.TextValue
+		}
+	}
+
+	if filter.SortID != 0 {
+		field, ok := fieldById[filter.SortID]
+		if !ok {
+			return filter, ErrCustomFieldNotFound
+		}
+		filter.SortColumn = field.ValueColumn()
+	}
+
+	return filter, nil
+}
+
+func parseCustomFieldValue(field entity.CustomField, raw interface{}) (entity.CustomFieldValue, error) {
+	value := entity.CustomFieldValue{FieldID: field.ID}
+	invalid := fmt.Errorf("%w: %q expects a %s value", ErrInvalidCustomField, field.Name, field.Type)
+
+	switch field.Type {
+	case entity.CustomFieldText, entity.CustomFieldSelect:
+		text, ok := raw.(string)
+		if !ok || len(text) > 255 {
+			return value, invalid
+		}
+
+		if field.Type == entity.CustomFieldSelect && !containsString(field.Options, text) {
+			return value, fmt.Errorf("%w: %q must be one of %s", ErrInvalidCustomField, field.Name, strings.Join(field.Options, ", "))
+		}
+		value.TextValue = &text
+	case entity.CustomFieldNumber:
+		var number float64
+		switch v := raw.(type) {
+		case float64:
+			number = v
+		case string:
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return value, invalid
+			}
+			number = parsed
+		default:
+			return value, invalid
+		}
+		value.NumberValue = &number
+	case entity.CustomFieldDate:
+		text, ok := raw.(string)
+		if !ok {
+			return value, invalid
+		}
+
+		date, err := time.Parse(entity.DateLayout, text)
+		if err != nil {
+			return value, invalid
+		}
+		value.DateValue = &date
+	case entity.CustomFieldCheckbox:
+		var checked bool
+		switch v := raw.(type) {
+		case bool:
+			checked = v
+		case string:
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return value, invalid
+			}
+			checked = parsed
+		default:
+			return value, invalid
+		}
+		value.BoolValue = &checked
+	default:
+		return value, invalid
+	}
+
+	return value, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindUnauthorized
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindValidation
+)
+
+// Error is a domain error a client can act on. Code is stable and part of
+// the API, Message may change.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []entity.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(kind ErrorKind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NewValidationError reports invalid input field by field.
+func NewValidationError(message string, fields []entity.FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: "validation_failed", Message: message, Fields: fields}
+}
+
+// AsError finds the domain error behind err. Repository errors that mean
+// something to a client are translated to their domain error.
+func AsError(err error) (*Error, bool) {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return ErrVersionConflict, true
+	}
+
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind before
+// it is dropped.
+const eventBufferSize = 64
+
+// EventBus fans board events out to the subscribers of that board.
+type EventBus interface {
+	Publish(event entity.BoardEvent)
+	// Subscribe returns the events of a board until unsubscribe is called.
+	// The channel is closed when the subscriber falls too far behind, so it
+	// has to reload the board and subscribe again.
+	Subscribe(boardId int) (events <-chan entity.BoardEvent, unsubscribe func())
+	// Listen calls listener with the events of every board in the order
+	// they are published. It runs while publishing, so it must not block.
+	Listen(listener func(entity.BoardEvent))
+}
+
+type eventBus struct {
+	mu          sync.Mutex
+	lastID      int64
+	subscribers map[int]map[chan entity.BoardEvent]struct{}
+	listeners   []func(entity.BoardEvent)
+}
+
+func NewEventBus() EventBus {
+	return &eventBus{subscribers: map[int]map[chan entity.BoardEvent]struct{}{}}
+}
+
+func (b *eventBus) Publish(event entity.BoardEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	for ch := range b.subscribers[event.BoardID] {
+		select {
+		case ch <- event:
+		default:
+			// never block a write on a slow reader
+			b.remove(event.BoardID, ch)
+		}
+	}
+
+	for _, listener := range b.listeners {
+		listener(event)
+	}
+}
+
+func (b *eventBus) Listen(listener func(entity.BoardEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.listeners = append(b.listeners, listener)
+}
+
+func (b *eventBus) Subscribe(boardId int) (<-chan entity.BoardEvent, func()) {
+	ch := make(chan entity.BoardEvent, eventBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[boardId] == nil {
+		b.subscribers[boardId] = map[chan entity.BoardEvent]struct{}{}
+	}
+	b.subscribers[boardId][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(boardId, ch)
+	}
+}
+
+// remove must be called with mu held.
+func (b *eventBus) remove(boardId int, ch chan entity.BoardEvent) {
+	subscribers, ok := b.subscribers[boardId]
+	if !ok {
+		return
+	}
+
+	if _, ok := subscribers[ch]; !ok {
+		return
+	}
+
+	delete(subscribers, ch)
+	close(ch)
+	if len(subscribers) == 0 {
+		delete(b.subscribers, boardId)
+	}
+}
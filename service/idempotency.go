@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var (
+	ErrIdempotencyKeyReused  = newError(KindValidation, "idempotency_key_reused", "idempotency key was already used for a different request")
+	ErrIdempotencyInProgress = newError(KindConflict, "idempotency_key_in_progress", "a request with this idempotency key is still in progress")
+)
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, userId int, key string, requestHash string) (entity.IdempotencyKey, error)
+	Complete(ctx context.Context, id int, statusCode int, header []string, body []byte) error
+	Release(ctx context.Context, id int) error
+}
+
+type idempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepository
+	window          time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, window time.Duration) IdempotencyService {
+	if window <= 0 {
+		window = entity.DefaultIdempotencyWindow
+	}
+	return &idempotencyService{idempotencyRepo, window}
+}
+
+// Begin reserves the key for a new request. When the key was used before
+// it returns the stored record, whose StatusCode, ResponseHeader and
+// ResponseBody should be replayed. A key whose request outlived its lease
+// is handed out again with a StatusCode of 0.
+func (s *idempotencyService) Begin(ctx context.Context, userId int, key string, requestHash string) (entity.IdempotencyKey, error) {
+	now := time.Now()
+
+	err := s.idempotencyRepo.DeleteExpiredKeys(ctx, now)
+	if err != nil {
+		return entity.IdempotencyKey{}, err
+	}
+
+	record := entity.IdempotencyKey{
+		UserID:      userId,
+		Key:         key,
+		RequestHash: requestHash,
+		LockedUntil: now.Add(entity.IdempotencyLease),
+		ExpiresAt:   now.Add(s.window),
+	}
+
+	reserved, err := s.idempotencyRepo.ReserveKey(ctx, &record)
+	if err != nil {
+		return entity.IdempotencyKey{}, err
+	}
+	if reserved {
+		return record, nil
+	}
+
+	stored, err := s.idempotencyRepo.GetKey(ctx, userId, key)
+	if err != nil {
+		return entity.IdempotencyKey{}, err
+	}
+
+	if stored.RequestHash != requestHash {
+		return entity.IdempotencyKey{}, ErrIdempotencyKeyReused
+	}
+
+	if stored.StatusCode == 0 {
+		reclaimed, err := s.idempotencyRepo.ReclaimKey(ctx, stored.ID, now, record.LockedUntil)
+		if err != nil {
+			return entity.IdempotencyKey{}, err
+		}
+		if !reclaimed {
+			return entity.IdempotencyKey{}, ErrIdempotencyInProgress
+		}
+		stored.LockedUntil = record.LockedUntil
+	}
+	return stored, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, id int, statusCode int, header []string, body []byte) error {
+	return s.idempotencyRepo.CompleteKey(ctx, id, statusCode, header, body)
+}
+
+// Release forgets a key so the request can be retried, used when the
+// request failed on the server side.
+func (s *idempotencyService) Release(ctx context.Context, id int) error {
+	return s.idempotencyRepo.DeleteKey(ctx, id)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+// LoaderService hands out the batching loaders of one GraphQL request.
+type LoaderService interface {
+	NewLoaders(ctx context.Context) *Loaders
+}
+
+// Loaders batch the lookups made while one request resolves, so a board
+// with n categories costs one query for their tasks instead of n. Results
+// are cached for the request, they are never shared between requests.
+type Loaders struct {
+	// Users loads an entity.User by id.
+	Users *Loader
+	// Categories loads an entity.Category by id.
+	Categories *Loader
+	// TasksByCategory loads the []entity.Task of a category id.
+	TasksByCategory *Loader
+}
+
+type loaderService struct {
+	taskRepo     repository.TaskRepository
+	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+}
+
+func NewLoaderService(taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository, userRepo repository.UserRepository) LoaderService {
+	return &loaderService{taskRepo, categoryRepo, userRepo}
+}
+
+func (s *loaderService) NewLoaders(ctx context.Context) *Loaders {
+	return &Loaders{
+		Users: newLoader(ctx, func(ctx context.Context, ids []int) (map[int]interface{}, error) {
+			users, err := s.userRepo.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			results := make(map[int]interface{}, len(users))
+			for _, user := range users {
+				results[user.ID] = user
+			}
+			return results, nil
+		}),
+		Categories: newLoader(ctx, func(ctx context.Context, ids []int) (map[int]interface{}, error) {
+			categories, err := s.categoryRepo.GetCategoriesByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			results := make(map[int]interface{}, len(categories))
+			for _, category := range categories {
+				results[category.ID] = category
+			}
+			return results, nil
+		}),
+		TasksByCategory: newLoader(ctx, func(ctx context.Context, ids []int) (map[int]interface{}, error) {
+			tasks, err := s.taskRepo.GetTasksByCategoryIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byCategory := make(map[int][]entity.Task, len(ids))
+			for _, task := range tasks {
+				byCategory[task.CategoryID] = append(byCategory[task.CategoryID], task)
+			}
+
+			results := make(map[int]interface{}, len(ids))
+			for _, id := range ids {
+				// an empty list rather than null for categories without tasks
+				results[id] = append([]entity.Task{}, byCategory[id]...)
+			}
+			return results, nil
+		}),
+	}
+}
+
+// Loader collects the keys asked for while one level of a query resolves
+// and fetches them together when the first of the results is needed.
+type Loader struct {
+	ctx     context.Context
+	fetch   func(ctx context.Context, keys []int) (map[int]interface{}, error)
+	mu      sync.Mutex
+	pending []int
+	queued  map[int]bool
+	results map[int]interface{}
+	errs    map[int]error
+}
+
+func newLoader(ctx context.Context, fetch func(ctx context.Context, keys []int) (map[int]interface{}, error)) *Loader {
+	return &Loader{
+		ctx:     ctx,
+		fetch:   fetch,
+		queued:  map[int]bool{},
+		results: map[int]interface{}{},
+		errs:    map[int]error{},
+	}
+}
+
+// Load queues key and returns a thunk for its value, nil when nothing was
+// found. Calling the thunk fetches every key queued so far.
+func (l *Loader) Load(key int) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+
+			results, err := l.fetch(l.ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.results[k] = results[k]
+			}
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var (
+	ErrInvalidMarkdownOptions = newError(KindInvalid, "invalid_markdown_options", "invalid markdown options")
+	ErrDoneCategoryNotFound   = newError(KindNotFound, "done_category_not_found", "the board has no Done category, pass the category_id of the done column")
+)
+
+type MarkdownService interface {
+	RenderBoard(ctx context.Context, userId int, options entity.MarkdownOptions) (string, error)
+}
+
+type markdownService struct {
+	taskRepo     repository.TaskRepository
+	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+}
+
+func NewMarkdownService(taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository, userRepo repository.UserRepository) MarkdownService {
+	return &markdownService{taskRepo, categoryRepo, userRepo}
+}
+
+// RenderBoard renders the active tasks of the board with one heading per
+// column and one checklist item per task, checked in the done column.
+// Completed tasks are those of the done column, the category named Done or
+// the one given, and their completion date is the last time they were
+// updated.
+func (s *markdownService) RenderBoard(ctx context.Context, userId int, options entity.MarkdownOptions) (string, error) {
+	switch options.GroupBy {
+	case "", entity.MarkdownGroupLabel, entity.MarkdownGroupAssignee:
+	default:
+		return "", fmt.Errorf("%w: group_by must be %s or %s", ErrInvalidMarkdownOptions, entity.MarkdownGroupLabel, entity.MarkdownGroupAssignee)
+	}
+	if !options.From.IsZero() && !options.To.IsZero() && options.To.Before(options.From) {
+		return "", fmt.Errorf("%w: to is before from", ErrInvalidMarkdownOptions)
+	}
+
+	categories, err := s.categoryRepo.GetCategoriesByUserId(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+
+	doneId := 0
+	found := false
+	for _, category := range categories {
+		if doneId == 0 && strings.EqualFold(category.Type, entity.DoneCategory) {
+			doneId = category.ID
+		}
+		found = found || category.ID == options.CategoryID
+	}
+	if options.CategoryID != 0 && !found {
+		return "", ErrCategoryNotFound
+	}
+
+	filter := entity.TaskFilter{CategoryID: options.CategoryID, Sort: "id"}
+	if options.Completed {
+		if filter.CategoryID == 0 {
+			if doneId == 0 {
+				return "", ErrDoneCategoryNotFound
+			}
+			filter.CategoryID = doneId
+		}
+		doneId = filter.CategoryID
+		filter.UpdatedFrom, filter.UpdatedTo = options.From, options.To
+	}
+
+	tasks, err := getAllTasks(ctx, s.taskRepo, userId, filter)
+	if err != nil {
+		return "", err
+	}
+
+	assignees := map[int]string{}
+	if options.GroupBy == entity.MarkdownGroupAssignee {
+		var ids []int
+		for _, task := range tasks {
+			if task.AssigneeID != nil {
+				ids = append(ids, *task.AssigneeID)
+			}
+		}
+
+		if len(ids) > 0 {
+			users, err := s.userRepo.GetUsersByIDs(ctx, uniqueIDs(ids))
+			if err != nil {
+				return "", err
+			}
+			for _, user := range users {
+				assignees[user.ID] = user.Fullname
+			}
+		}
+	}
+
+	tasksByCategory := map[int][]entity.Task{}
+	for _, task := range tasks {
+		tasksByCategory[task.CategoryID] = append(tasksByCategory[task.CategoryID], task)
+	}
+
+	lines := []string{"# Board"}
+	if options.Completed {
+		lines = []string{"# Completed tasks"}
+		switch {
+		case !options.From.IsZero() && !options.To.IsZero():
+			lines = append(lines, "", fmt.Sprintf("Completed from %s to %s.", options.From.Format(entity.DateLayout), options.To.Format(entity.DateLayout)))
+		case !options.From.IsZero():
+			lines = append(lines, "", fmt.Sprintf("Completed since %s.", options.From.Format(entity.DateLayout)))
+		case !options.To.IsZero():
+			lines = append(lines, "", fmt.Sprintf("Completed until %s.", options.To.Format(entity.DateLayout)))
+		}
+	}
+
+	for _, category := range categories {
+		if filter.CategoryID != 0 && category.ID != filter.CategoryID {
+			continue
+		}
+
+		lines = append(lines, "", "## "+escapeMarkdown(category.Type), "")
+		columnTasks := tasksByCategory[category.ID]
+		if len(columnTasks) == 0 {
+			lines = append(lines, "_No tasks_")
+			continue
+		}
+
+		done := category.ID == doneId
+		if options.GroupBy == "" {
+			for _, task := range columnTasks {
+				lines = append(lines, markdownTask(task, done, true))
+			}
+			continue
+		}
+
+		for i, group := range groupTasks(columnTasks, options.GroupBy, assignees) {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, "### "+escapeMarkdown(group.name), "")
+			for _, task := range group.tasks {
+				lines = append(lines, markdownTask(task, done, options.GroupBy != entity.MarkdownGroupLabel))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+type markdownGroup struct {
+	name  string
+	tasks []entity.Task
+}
+
+// groupTasks groups tasks by label, a task being listed under each of its
+// labels, or by assignee. Groups are sorted by name, the group of tasks
+// without a label or assignee comes last.
+func groupTasks(tasks []entity.Task, groupBy string, assignees map[int]string) []markdownGroup {
+	none := "No label"
+	if groupBy == entity.MarkdownGroupAssignee {
+		none = "Unassigned"
+	}
+
+	groups := map[string]*markdownGroup{}
+	add := func(name string, task entity.Task) {
+		if groups[name] == nil {
+			groups[name] = &markdownGroup{name: name}
+		}
+		groups[name].tasks = append(groups[name].tasks, task)
+	}
+
+	for _, task := range tasks {
+		switch {
+		case groupBy == entity.MarkdownGroupLabel && len(task.Labels) > 0:
+			for _, label := range task.Labels {
+				add(label, task)
+			}
+		case groupBy == entity.MarkdownGroupAssignee && task.AssigneeID != nil && assignees[*task.AssigneeID] != "":
+			add(assignees[*task.AssigneeID], task)
+		default:
+			add(none, task)
+		}
+	}
+
+	var names []string
+	for name := range groups {
+		if name != none {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if groups[none] != nil {
+		names = append(names, none)
+	}
+
+	sorted := make([]markdownGroup, 0, len(names))
+	for _, name := range names {
+		sorted = append(sorted, *groups[name])
+	}
+	return sorted
+}
+
+func markdownTask(task entity.Task, done bool, withLabels bool) string {
+	box := "[ ]"
+	if done {
+		box = "[x]"
+	}
+
+	line := fmt.Sprintf("- %s %s", box, escapeMarkdown(task.Title))
+	if withLabels && len(task.Labels) > 0 {
+		labels := make([]string, len(task.Labels))
+		for i, label := range task.Labels {
+			labels[i] = escapeMarkdown(label)
+		}
+		line += " (" + strings.Join(labels, ", ") + ")"
+	}
+	return line
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]",
+	"<", "\\<", ">", "\\>", "#", "\\#", "|", "\\|", "\r\n", " ", "\n", " ",
+)
+
+// escapeMarkdown keeps user text from being read as Markdown syntax and on
+// one line.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
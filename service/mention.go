@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+	"github.com/snykk/kanban-app/utils"
+)
+
+// recordMentions resolves the @mentions in text, stores a mention record
+// for each user found and notifies the ones that were not already
+// mentioned. Unresolvable tokens are ignored.
+func recordMentions(ctx context.Context, userRepo repository.UserRepository, notificationRepo repository.NotificationRepository, actorId int, task entity.Task, commentId int, text string, alreadyMentioned map[int]bool) error {
+	var mentions []entity.Mention
+	var notifications []entity.Notification
+
+	for _, token := range utils.ParseMentions(text) {
+		var user entity.User
+		var err error
+
+		if strings.Contains(token, "@") {
+			user, err = userRepo.GetUserByEmail(ctx, token)
+		} else {
+			user, err = userRepo.GetUserByHandle(ctx, token)
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.ID == 0 {
+			continue
+		}
+
+		mentions = append(mentions, entity.Mention{
+			TaskID:    task.ID,
+			CommentID: commentId,
+			UserID:    user.ID,
+			ActorID:   actorId,
+			Token:     token,
+		})
+
+		if user.ID == actorId || alreadyMentioned[user.ID] {
+			continue
+		}
+
+		alreadyMentioned[user.ID] = true
+		notifications = append(notifications, entity.Notification{
+			UserID:  user.ID,
+			ActorID: actorId,
+			TaskID:  task.ID,
+			Type:    entity.NotificationMentioned,
+			Message: fmt.Sprintf("You were mentioned in task %q", task.Title),
+		})
+	}
+
+	err := notificationRepo.StoreMentions(ctx, mentions)
+	if err != nil {
+		return err
+	}
+
+	err = notificationRepo.StoreNotifications(ctx, notifications)
+	if err != nil {
+		log.Println("store notifications:", err.Error())
+	}
+	return nil
+}
+
+// recordDescriptionMentions replaces the mentions of a task description.
+// Users that were already mentioned before the edit are not notified again.
+func recordDescriptionMentions(ctx context.Context, userRepo repository.UserRepository, notificationRepo repository.NotificationRepository, actorId int, task entity.Task) error {
+	previous, err := notificationRepo.GetMentionsByTaskIDs(ctx, []int{task.ID})
+	if err != nil {
+		return err
+	}
+
+	alreadyMentioned := map[int]bool{}
+	for _, mention := range previous {
+		if mention.CommentID == 0 {
+			alreadyMentioned[mention.UserID] = true
+		}
+	}
+
+	err = notificationRepo.DeleteDescriptionMentions(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	return recordMentions(ctx, userRepo, notificationRepo, actorId, task, 0, task.Description, alreadyMentioned)
+}
+
+// attachMentions fills in the description mentions of each task.
+func attachMentions(ctx context.Context, notificationRepo repository.NotificationRepository, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	taskIds := make([]int, len(tasks))
+	for i, task := range tasks {
+		taskIds[i] = task.ID
+	}
+
+	mentions, err := notificationRepo.GetMentionsByTaskIDs(ctx, taskIds)
+	if err != nil {
+		return err
+	}
+
+	mentionsByTask := make(map[int][]entity.Mention)
+	for _, mention := range mentions {
+		if mention.CommentID == 0 {
+			mentionsByTask[mention.TaskID] = append(mentionsByTask[mention.TaskID], mention)
+		}
+	}
+
+	for i := range tasks {
+		tasks[i].Mentions = mentionsByTask[tasks[i].ID]
+	}
+
+	return nil
+}
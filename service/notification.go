@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+type NotificationService interface {
+	WatchTask(ctx context.Context, userId, taskId int) error
+	UnwatchTask(ctx context.Context, userId, taskId int) error
+	GetNotifications(ctx context.Context, id int, unreadOnly bool) ([]entity.Notification, error)
+	CountUnread(ctx context.Context, id int) (int64, error)
+	MarkRead(ctx context.Context, id int, notificationIds []int) error
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	taskRepo         repository.TaskRepository
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository, taskRepo repository.TaskRepository) NotificationService {
+	return &notificationService{notificationRepo, taskRepo}
+}
+
+func (s *notificationService) WatchTask(ctx context.Context, userId, taskId int) error {
+	task, err := s.taskRepo.GetTaskByID(ctx, taskId)
+	if err != nil {
+		return err
+	}
+
+	if task.ID == 0 || task.UserID != userId {
+		return ErrTaskNotFound
+	}
+
+	return s.notificationRepo.StoreWatcher(ctx, &entity.Watcher{TaskID: taskId, UserID: userId})
+}
+
+func (s *notificationService) UnwatchTask(ctx context.Context, userId, taskId int) error {
+	return s.notificationRepo.DeleteWatcher(ctx, taskId, userId)
+}
+
+func (s *notificationService) GetNotifications(ctx context.Context, id int, unreadOnly bool) ([]entity.Notification, error) {
+	return s.notificationRepo.GetNotificationsByUserId(ctx, id, unreadOnly)
+}
+
+func (s *notificationService) CountUnread(ctx context.Context, id int) (int64, error) {
+	return s.notificationRepo.CountUnread(ctx, id)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, id int, notificationIds []int) error {
+	return s.notificationRepo.MarkRead(ctx, id, notificationIds)
+}
+
+// notifyWatchers records a notification for every watcher of the task
+// except the user who made the change. Failures are logged rather than
+// returned so a notification problem never rolls back the change itself.
+func notifyWatchers(ctx context.Context, notificationRepo repository.NotificationRepository, actorId int, task entity.Task, notificationType string) {
+	watchers, err := notificationRepo.GetWatchersByTaskID(ctx, task.ID)
+	if err != nil {
+		log.Println("get watchers:", err.Error())
+		return
+	}
+
+	var notifications []entity.Notification
+	for _, watcher := range watchers {
+		if watcher.UserID == actorId {
+			continue
+		}
+
+		notifications = append(notifications, entity.Notification{
+			UserID:  watcher.UserID,
+			ActorID: actorId,
+			TaskID:  task.ID,
+			Type:    notificationType,
+			Message: notificationMessage(task, notificationType),
+		})
+	}
+
+	err = notificationRepo.StoreNotifications(ctx, notifications)
+	if err != nil {
+		log.Println("store notifications:", err.Error())
+	}
+}
+
+// notifyAssignee tells a user a task was assigned to them, unless they
+// assigned it themselves.
+func notifyAssignee(ctx context.Context, notificationRepo repository.NotificationRepository, actorId int, assigneeId int, task entity.Task) {
+	if assigneeId == actorId {
+		return
+	}
+
+	err := notificationRepo.StoreNotifications(ctx, []entity.Notification{{
+		UserID:  assigneeId,
+		ActorID: actorId,
+		TaskID:  task.ID,
+		Type:    entity.NotificationAssigned,
+		Message: notificationMessage(task, entity.NotificationAssigned),
+	}})
+	if err != nil {
+		log.Println("store notifications:", err.Error())
+	}
+}
+
+func notificationMessage(task entity.Task, notificationType string) string {
+	switch notificationType {
+	case entity.NotificationMoved:
+		return fmt.Sprintf("Task %q was moved to another column", task.Title)
+	case entity.NotificationCommented:
+		return fmt.Sprintf("New comment on task %q", task.Title)
+	case entity.NotificationAssigned:
+		return fmt.Sprintf("Task %q was assigned to you", task.Title)
+	default:
+		return fmt.Sprintf("Task %q was edited", task.Title)
+	}
+}
@@ -2,45 +2,165 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"strings"
 
 	"github.com/snykk/kanban-app/entity"
 	"github.com/snykk/kanban-app/repository"
 )
 
+// ErrVersionConflict is returned when a write was made against a stale
+// version of a task or category.
+var ErrVersionConflict = newError(KindPreconditionFailed, "version_conflict", repository.ErrVersionConflict.Error())
+
+var (
+	ErrInvalidBulkRequest = newError(KindInvalid, "invalid_bulk_request", "invalid bulk request")
+	ErrAssigneeNotFound   = newError(KindNotFound, "assignee_not_found", "assignee not found")
+	// ErrBulkRejected is returned with the per-task results when some of
+	// the tasks cannot be changed and nothing was applied.
+	ErrBulkRejected = newError(KindValidation, "bulk_rejected", "some tasks cannot be changed, nothing was applied")
+)
+
 type TaskService interface {
 	GetTasks(ctx context.Context, id int) ([]entity.Task, error)
+	GetTasksPage(ctx context.Context, id int, filter entity.TaskFilter) (entity.TaskPage, error)
+	SearchTasks(ctx context.Context, id int, filter entity.TaskSearchFilter) (entity.TaskSearchResponse, error)
 	GetTaskByID(ctx context.Context, id int) (entity.Task, error)
 	StoreTask(ctx context.Context, task *entity.Task) (entity.Task, error)
 	UpdateTask(ctx context.Context, task *entity.Task) (entity.Task, error)
-	DeleteTask(ctx context.Context, id int) error
+	PatchTask(ctx context.Context, userId int, id int, patch entity.TaskPatch) (entity.Task, error)
+	DeleteTask(ctx context.Context, userId int, id int, version int) error
+	BulkTasks(ctx context.Context, userId int, req entity.BulkTaskRequest) (entity.BulkTaskResponse, error)
 }
 
 type taskService struct {
-	taskRepo     repository.TaskRepository
-	categoryRepo repository.CategoryRepository
+	taskRepo         repository.TaskRepository
+	categoryRepo     repository.CategoryRepository
+	customFieldRepo  repository.CustomFieldRepository
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	eventBus         EventBus
 }
 
-func NewTaskService(taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository) TaskService {
-	return &taskService{taskRepo, categoryRepo}
+func NewTaskService(taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository, customFieldRepo repository.CustomFieldRepository, notificationRepo repository.NotificationRepository, userRepo repository.UserRepository, eventBus EventBus) TaskService {
+	return &taskService{taskRepo, categoryRepo, customFieldRepo, notificationRepo, userRepo, eventBus}
 }
 
 func (s *taskService) GetTasks(ctx context.Context, id int) ([]entity.Task, error) {
-	return s.taskRepo.GetTasks(ctx, id)
+	tasks, err := s.taskRepo.GetTasks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachCustomFields(ctx, s.customFieldRepo, id, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachMentions(ctx, s.notificationRepo, tasks)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *taskService) GetTasksPage(ctx context.Context, id int, filter entity.TaskFilter) (entity.TaskPage, error) {
+	var err error
+	if filter.CustomField.FieldID != 0 || filter.CustomField.SortID != 0 {
+		filter.CustomField, err = resolveCustomFieldFilter(ctx, s.customFieldRepo, id, filter.CustomField)
+		if err != nil {
+			return entity.TaskPage{}, err
+		}
+	}
+
+	tasks, total, err := s.taskRepo.GetTasksByFilter(ctx, id, filter)
+	if err != nil {
+		return entity.TaskPage{}, err
+	}
+
+	err = attachCustomFields(ctx, s.customFieldRepo, id, tasks)
+	if err != nil {
+		return entity.TaskPage{}, err
+	}
+
+	err = attachMentions(ctx, s.notificationRepo, tasks)
+	if err != nil {
+		return entity.TaskPage{}, err
+	}
+	return entity.NewTaskPage(tasks, total, filter), nil
+}
+
+func (s *taskService) SearchTasks(ctx context.Context, id int, filter entity.TaskSearchFilter) (entity.TaskSearchResponse, error) {
+	results, err := s.taskRepo.SearchTasks(ctx, id, filter)
+	if err != nil {
+		return entity.TaskSearchResponse{}, err
+	}
+
+	if results == nil {
+		results = []entity.TaskSearchResult{}
+	}
+	return entity.TaskSearchResponse{Query: filter.Query, Results: results}, nil
 }
 
 func (s *taskService) StoreTask(ctx context.Context, task *entity.Task) (entity.Task, error) {
-	_, err := s.taskRepo.StoreTask(ctx, task)
+	values, cleared, err := buildCustomFieldValues(ctx, s.customFieldRepo, task.UserID, task.CustomFields)
 	if err != nil {
 		return entity.Task{}, err
 	}
+
+	_, err = s.taskRepo.StoreTask(ctx, task)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = saveCustomFieldValues(ctx, s.customFieldRepo, task.ID, values, cleared)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = recordDescriptionMentions(ctx, s.userRepo, s.notificationRepo, task.UserID, *task)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	s.publishTask(ctx, entity.EventTaskCreated, task.UserID, task.ID)
 	return *task, nil
 }
 
 func (s *taskService) GetTaskByID(ctx context.Context, id int) (entity.Task, error) {
-	return s.taskRepo.GetTaskByID(ctx, id)
+	task, err := s.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	tasks := []entity.Task{task}
+	err = attachCustomFields(ctx, s.customFieldRepo, task.UserID, tasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = attachMentions(ctx, s.notificationRepo, tasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	return tasks[0], nil
 }
 
 func (s *taskService) UpdateTask(ctx context.Context, task *entity.Task) (entity.Task, error) {
+	dbTask, err := s.taskRepo.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if dbTask.ID == 0 || (task.UserID != 0 && dbTask.UserID != task.UserID) {
+		return entity.Task{}, ErrTaskNotFound
+	}
+
+	if task.Version != 0 && task.Version != dbTask.Version {
+		return entity.Task{}, ErrVersionConflict
+	}
+
 	if task.CategoryID != 0 {
 		cat, err := s.categoryRepo.GetCategoryByID(ctx, task.CategoryID)
 		if err != nil {
@@ -48,17 +168,297 @@ func (s *taskService) UpdateTask(ctx context.Context, task *entity.Task) (entity
 		}
 
 		if cat.ID == 0 || cat.Type == "" || cat.UserID != task.UserID {
-			return entity.Task{}, err
+			return entity.Task{}, ErrCategoryNotFound
 		}
 	}
 
-	err := s.taskRepo.UpdateTask(ctx, task)
+	values, cleared, err := buildCustomFieldValues(ctx, s.customFieldRepo, task.UserID, task.CustomFields)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = s.taskRepo.UpdateTask(ctx, task)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = saveCustomFieldValues(ctx, s.customFieldRepo, task.ID, values, cleared)
 	if err != nil {
 		return entity.Task{}, err
 	}
+
+	notificationType := entity.NotificationEdited
+	if task.CategoryID != 0 && task.CategoryID != dbTask.CategoryID {
+		notificationType = entity.NotificationMoved
+	}
+
+	if task.Title != "" {
+		dbTask.Title = task.Title
+	}
+
+	if task.Description != "" && task.Description != dbTask.Description {
+		dbTask.Description = task.Description
+		err = recordDescriptionMentions(ctx, s.userRepo, s.notificationRepo, dbTask.UserID, dbTask)
+		if err != nil {
+			return entity.Task{}, err
+		}
+	}
+
+	notifyWatchers(ctx, s.notificationRepo, dbTask.UserID, dbTask, notificationType)
+
+	eventType := entity.EventTaskUpdated
+	if notificationType == entity.NotificationMoved {
+		eventType = entity.EventTaskMoved
+	}
+	s.publishTask(ctx, eventType, dbTask.UserID, task.ID)
+
 	return *task, nil
 }
 
-func (s *taskService) DeleteTask(ctx context.Context, id int) error {
-	return s.taskRepo.DeleteTask(ctx, id)
+func (s *taskService) PatchTask(ctx context.Context, userId int, id int, patch entity.TaskPatch) (entity.Task, error) {
+	dbTask, err := s.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if dbTask.ID == 0 || dbTask.UserID != userId {
+		return entity.Task{}, ErrTaskNotFound
+	}
+
+	if patch.Version != 0 && patch.Version != dbTask.Version {
+		return entity.Task{}, ErrVersionConflict
+	}
+
+	fields := map[string]interface{}{}
+	if patch.CategoryID != nil && *patch.CategoryID != dbTask.CategoryID {
+		cat, err := s.categoryRepo.GetCategoryByID(ctx, *patch.CategoryID)
+		if err != nil {
+			return entity.Task{}, err
+		}
+
+		if cat.ID == 0 || cat.UserID != userId {
+			return entity.Task{}, ErrCategoryNotFound
+		}
+		fields["category_id"] = cat.ID
+	}
+	if patch.Title != nil {
+		fields["title"] = *patch.Title
+	}
+	if patch.Description != nil {
+		fields["description"] = *patch.Description
+	}
+	if patch.Estimate != nil {
+		fields["estimate"] = *patch.Estimate
+	}
+	if patch.DueDate != nil {
+		fields["due_date"] = patch.DueDate
+		if patch.DueDate.IsZero() {
+			fields["due_date"] = nil
+		}
+	}
+
+	values, cleared, err := buildCustomFieldValues(ctx, s.customFieldRepo, userId, patch.CustomFields)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if len(fields) > 0 || len(patch.CustomFields) > 0 {
+		_, err = s.taskRepo.PatchTask(ctx, id, patch.Version, fields)
+		if err != nil {
+			return entity.Task{}, err
+		}
+	}
+
+	err = saveCustomFieldValues(ctx, s.customFieldRepo, id, values, cleared)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if patch.Description != nil && *patch.Description != dbTask.Description {
+		dbTask.Description = *patch.Description
+		err = recordDescriptionMentions(ctx, s.userRepo, s.notificationRepo, userId, dbTask)
+		if err != nil {
+			return entity.Task{}, err
+		}
+	}
+
+	task, err := s.GetTaskByID(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if len(fields) > 0 || len(patch.CustomFields) > 0 {
+		notificationType, eventType := entity.NotificationEdited, entity.EventTaskUpdated
+		if _, moved := fields["category_id"]; moved {
+			notificationType, eventType = entity.NotificationMoved, entity.EventTaskMoved
+		}
+		notifyWatchers(ctx, s.notificationRepo, userId, task, notificationType)
+		s.eventBus.Publish(taskEvent(eventType, userId, task))
+	}
+
+	return task, nil
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, userId int, id int, version int) error {
+	task, err := s.taskRepo.GetTaskByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if task.ID == 0 || task.UserID != userId {
+		return ErrTaskNotFound
+	}
+
+	err = s.taskRepo.DeleteTask(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	s.eventBus.Publish(entity.BoardEvent{Type: entity.EventTaskDeleted, BoardID: task.UserID, ActorID: userId, TaskID: task.ID, CategoryID: task.CategoryID})
+	return nil
+}
+
+func (s *taskService) BulkTasks(ctx context.Context, userId int, req entity.BulkTaskRequest) (entity.BulkTaskResponse, error) {
+	ids := uniqueIDs(req.TaskIDs)
+	if len(ids) == 0 || len(ids) > entity.MaxBulkTasks {
+		return entity.BulkTaskResponse{}, fmt.Errorf("%w: expected 1 to %d task ids", ErrInvalidBulkRequest, entity.MaxBulkTasks)
+	}
+
+	switch req.Action {
+	case entity.BulkActionDelete, entity.BulkActionArchive:
+	case entity.BulkActionMove:
+		cat, err := s.categoryRepo.GetCategoryByID(ctx, req.CategoryID)
+		if err != nil {
+			return entity.BulkTaskResponse{}, err
+		}
+
+		if cat.ID == 0 || cat.UserID != userId {
+			return entity.BulkTaskResponse{}, ErrCategoryNotFound
+		}
+	case entity.BulkActionAddLabel:
+		req.Label = strings.TrimSpace(req.Label)
+		if req.Label == "" || len(req.Label) > entity.MaxLabelLength {
+			return entity.BulkTaskResponse{}, fmt.Errorf("%w: label must be 1 to %d characters", ErrInvalidBulkRequest, entity.MaxLabelLength)
+		}
+	case entity.BulkActionAssign:
+		assignee, err := s.userRepo.GetUserByID(ctx, req.AssigneeID)
+		if err != nil {
+			return entity.BulkTaskResponse{}, err
+		}
+
+		if assignee.ID == 0 {
+			return entity.BulkTaskResponse{}, ErrAssigneeNotFound
+		}
+	default:
+		return entity.BulkTaskResponse{}, fmt.Errorf("%w: unknown action %q", ErrInvalidBulkRequest, req.Action)
+	}
+
+	tasks, err := s.taskRepo.GetTasksByIDs(ctx, ids)
+	if err != nil {
+		return entity.BulkTaskResponse{}, err
+	}
+
+	taskById := make(map[int]entity.Task, len(tasks))
+	for _, task := range tasks {
+		if task.UserID == userId {
+			taskById[task.ID] = task
+		}
+	}
+
+	response := entity.BulkTaskResponse{Action: req.Action}
+	rejected := false
+	for _, id := range ids {
+		result := entity.BulkTaskResult{TaskID: id, Status: entity.BulkStatusOK}
+		if _, ok := taskById[id]; !ok {
+			result = entity.BulkTaskResult{TaskID: id, Status: entity.BulkStatusNotFound, Error: ErrTaskNotFound.Error()}
+			rejected = true
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if rejected {
+		for i := range response.Results {
+			if response.Results[i].Status == entity.BulkStatusOK {
+				response.Results[i].Status = entity.BulkStatusSkipped
+			}
+		}
+		return response, ErrBulkRejected
+	}
+
+	err = s.taskRepo.BulkTasks(ctx, userId, ids, req)
+	if err != nil {
+		return entity.BulkTaskResponse{}, err
+	}
+	response.Applied = true
+
+	for _, id := range ids {
+		task := taskById[id]
+
+		switch req.Action {
+		case entity.BulkActionMove:
+			if task.CategoryID != req.CategoryID {
+				notifyWatchers(ctx, s.notificationRepo, userId, task, entity.NotificationMoved)
+			}
+		case entity.BulkActionAssign:
+			notifyAssignee(ctx, s.notificationRepo, userId, req.AssigneeID, task)
+		}
+	}
+
+	s.publishBulk(ctx, userId, ids, req, taskById)
+
+	return response, nil
+}
+
+// publishBulk reports each task touched by a bulk action. Archived tasks
+// are sent as updates carrying their archived_at.
+func (s *taskService) publishBulk(ctx context.Context, userId int, ids []int, req entity.BulkTaskRequest, before map[int]entity.Task) {
+	if req.Action == entity.BulkActionDelete {
+		for _, id := range ids {
+			s.eventBus.Publish(entity.BoardEvent{Type: entity.EventTaskDeleted, BoardID: userId, ActorID: userId, TaskID: id, CategoryID: before[id].CategoryID})
+		}
+		return
+	}
+
+	tasks, err := s.taskRepo.GetTasksByIDs(ctx, ids)
+	if err != nil {
+		log.Println("publish bulk events:", err.Error())
+		return
+	}
+
+	for _, task := range tasks {
+		eventType := entity.EventTaskUpdated
+		if req.Action == entity.BulkActionMove && before[task.ID].CategoryID != task.CategoryID {
+			eventType = entity.EventTaskMoved
+		}
+		s.eventBus.Publish(taskEvent(eventType, userId, task))
+	}
+}
+
+// publishTask sends the stored state of a task to its board. A failed read
+// only costs the event, the write has already gone through.
+func (s *taskService) publishTask(ctx context.Context, eventType string, actorId int, id int) {
+	task, err := s.GetTaskByID(ctx, id)
+	if err != nil {
+		log.Println("publish task event:", err.Error())
+		return
+	}
+
+	s.eventBus.Publish(taskEvent(eventType, actorId, task))
+}
+
+func taskEvent(eventType string, actorId int, task entity.Task) entity.BoardEvent {
+	return entity.BoardEvent{Type: eventType, BoardID: task.UserID, ActorID: actorId, TaskID: task.ID, CategoryID: task.CategoryID, Task: &task}
+}
+
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+
+	var unique []int
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
 }
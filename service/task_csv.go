@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var ErrInvalidCSVColumn = newError(KindInvalid, "invalid_csv_column", "invalid csv column")
+
+type TaskCSVService interface {
+	ExportTasks(ctx context.Context, userId int, options entity.TaskCSVExportOptions) ([][]string, error)
+	ImportTasks(ctx context.Context, userId int, req entity.TaskCSVImportRequest) (entity.TaskCSVImportResult, error)
+}
+
+type taskCSVService struct {
+	taskRepo     repository.TaskRepository
+	categoryRepo repository.CategoryRepository
+	eventBus     EventBus
+}
+
+func NewTaskCSVService(taskRepo repository.TaskRepository, categoryRepo repository.CategoryRepository, eventBus EventBus) TaskCSVService {
+	return &taskCSVService{taskRepo, categoryRepo, eventBus}
+}
+
+// ExportTasks returns the header and one record per task matching the
+// filter of the options.
+func (s *taskCSVService) ExportTasks(ctx context.Context, userId int, options entity.TaskCSVExportOptions) ([][]string, error) {
+	columns := options.Columns
+	if len(columns) == 0 {
+		columns = entity.TaskCSVColumns
+	}
+	for _, column := range columns {
+		if !containsString(entity.TaskCSVColumns, column) {
+			return nil, fmt.Errorf("%w: unknown column %q, expected any of %s", ErrInvalidCSVColumn, column, strings.Join(entity.TaskCSVColumns, ", "))
+		}
+	}
+
+	categories, err := s.categoryRepo.GetCategoriesByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[int]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Type
+	}
+
+	tasks, err := getAllTasks(ctx, s.taskRepo, userId, options.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{columns}
+	for _, task := range tasks {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = taskCSVValue(task, column, categoryNames)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// getAllTasks reads every task matching the filter, a page at a time. The
+// page of the filter is ignored.
+func getAllTasks(ctx context.Context, taskRepo repository.TaskRepository, userId int, filter entity.TaskFilter) ([]entity.Task, error) {
+	var tasks []entity.Task
+	filter.PerPage = entity.MaxTaskPerPage
+	for filter.Page = 1; ; filter.Page++ {
+		page, total, err := taskRepo.GetTasksByFilter(ctx, userId, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, page...)
+		if len(page) == 0 || int64(filter.Page*filter.PerPage) >= total {
+			return tasks, nil
+		}
+	}
+}
+
+func taskCSVValue(task entity.Task, column string, categoryNames map[int]string) string {
+	formatTime := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	switch column {
+	case "id":
+		return strconv.Itoa(task.ID)
+	case "title":
+		return escapeCSVFormula(task.Title)
+	case "description":
+		return escapeCSVFormula(task.Description)
+	case "category":
+		return escapeCSVFormula(categoryNames[task.CategoryID])
+	case "category_id":
+		return strconv.Itoa(task.CategoryID)
+	case "estimate":
+		return strconv.FormatFloat(task.Estimate, 'f', -1, 64)
+	case "labels":
+		return escapeCSVFormula(strings.Join(task.Labels, entity.CSVLabelSeparator))
+	case "due_date":
+		if task.DueDate == nil {
+			return ""
+		}
+		return task.DueDate.Format(entity.DateLayout)
+	case "assignee_id":
+		if task.AssigneeID == nil {
+			return ""
+		}
+		return strconv.Itoa(*task.AssigneeID)
+	case "archived_at":
+		if task.ArchivedAt == nil {
+			return ""
+		}
+		return formatTime(*task.ArchivedAt)
+	case "created_at":
+		return formatTime(task.CreatedAt)
+	case "updated_at":
+		return formatTime(task.UpdatedAt)
+	}
+	return ""
+}
+
+// escapeCSVFormula prefixes a cell that spreadsheets would read as a
+// formula with a quote, so user text exported to CSV is shown and not run.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
+
+// ImportTasks creates one task per row of the CSV file. Every row is
+// checked first and nothing is created when any of them is invalid. The
+// missing categories and the tasks are then created in one transaction, so
+// a failure leaves nothing behind either. Rows are numbered as in a
+// spreadsheet, the header being row 1. Categories are matched by name,
+// ignoring case.
+func (s *taskCSVService) ImportTasks(ctx context.Context, userId int, req entity.TaskCSVImportRequest) (entity.TaskCSVImportResult, error) {
+	var fields []entity.FieldError
+	invalid := func(field string, format string, args ...interface{}) {
+		fields = append(fields, entity.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	reader := csv.NewReader(strings.NewReader(req.CSV))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return entity.TaskCSVImportResult{}, NewValidationError("invalid csv", []entity.FieldError{{Field: "csv", Message: err.Error()}})
+	}
+	if len(records) < 2 {
+		return entity.TaskCSVImportResult{}, NewValidationError("invalid csv", []entity.FieldError{{Field: "csv", Message: "needs a header row and at least one task row"}})
+	}
+	if len(records)-1 > entity.MaxTaskCSVRows {
+		return entity.TaskCSVImportResult{}, NewValidationError("invalid csv", []entity.FieldError{{Field: "csv", Message: fmt.Sprintf("must have at most %d rows", entity.MaxTaskCSVRows)}})
+	}
+
+	header := map[string]int{}
+	for i, name := range records[0] {
+		if i == 0 {
+			// spreadsheets often save UTF-8 with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if _, ok := header[strings.TrimSpace(name)]; !ok {
+			header[strings.TrimSpace(name)] = i
+		}
+	}
+
+	mapped := make([]string, 0, len(req.Mapping))
+	for field := range req.Mapping {
+		mapped = append(mapped, field)
+	}
+	sort.Strings(mapped)
+
+	columns := map[string]int{}
+	for _, field := range mapped {
+		if !containsString(entity.TaskCSVImportFields, field) {
+			invalid("mapping."+field, "unknown task field, expected any of %s", strings.Join(entity.TaskCSVImportFields, ", "))
+			continue
+		}
+		position, ok := header[strings.TrimSpace(req.Mapping[field])]
+		if !ok {
+			invalid("mapping."+field, "column %q is not in the header", req.Mapping[field])
+			continue
+		}
+		columns[field] = position
+	}
+	if _, ok := req.Mapping["title"]; !ok {
+		invalid("mapping.title", "is required")
+	}
+	if _, ok := req.Mapping["category"]; !ok && req.CategoryID == 0 {
+		invalid("mapping.category", "is required without category_id")
+	}
+	if len(fields) > 0 {
+		return entity.TaskCSVImportResult{}, NewValidationError("invalid csv mapping", fields)
+	}
+
+	if req.CategoryID != 0 {
+		category, err := s.categoryRepo.GetCategoryByID(ctx, req.CategoryID)
+		if err != nil {
+			return entity.TaskCSVImportResult{}, err
+		}
+		if category.ID == 0 || category.UserID != userId {
+			return entity.TaskCSVImportResult{}, ErrCategoryNotFound
+		}
+	}
+
+	categories, err := s.categoryRepo.GetCategoriesByUserId(ctx, userId)
+	if err != nil {
+		return entity.TaskCSVImportResult{}, err
+	}
+	categoryIds := map[string]int{}
+	for _, category := range categories {
+		if _, ok := categoryIds[strings.ToLower(category.Type)]; !ok {
+			categoryIds[strings.ToLower(category.Type)] = category.ID
+		}
+	}
+
+	cell := func(record []string, field string) string {
+		position, ok := columns[field]
+		if !ok || position >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[position])
+	}
+
+	var tasks []entity.Task
+	var missing []string
+	// categoryOf maps the index of a task to the index of its category in
+	// missing
+	categoryOf := map[int]int{}
+	for i, record := range records[1:] {
+		row := fmt.Sprintf("rows[%d]", i+2)
+
+		task := entity.Task{
+			Title:       cell(record, "title"),
+			Description: cell(record, "description"),
+			CategoryID:  req.CategoryID,
+			UserID:      userId,
+			Labels:      []string{},
+		}
+		if task.Title == "" || len(task.Title) > 255 {
+			invalid(row+".title", "must be 1 to 255 characters")
+		}
+
+		if estimate := cell(record, "estimate"); estimate != "" {
+			task.Estimate, err = strconv.ParseFloat(estimate, 64)
+			if err != nil || !entity.IsValidEstimate(task.Estimate) {
+				invalid(row+".estimate", "must be a number from 0 to %d", entity.MaxTaskEstimate)
+			}
+		}
+
+		if dueDate := cell(record, "due_date"); dueDate != "" {
+			parsed, err := time.Parse(entity.DateLayout, dueDate)
+			if err != nil {
+				invalid(row+".due_date", "must be a date as YYYY-MM-DD")
+			} else {
+				task.DueDate = &parsed
+			}
+		}
+
+		for _, label := range strings.Split(cell(record, "labels"), entity.CSVLabelSeparator) {
+			label = strings.TrimSpace(label)
+			if label == "" || containsString(task.Labels, label) {
+				continue
+			}
+			if len(label) > entity.MaxLabelLength {
+				invalid(row+".labels", "label %q is longer than %d characters", label, entity.MaxLabelLength)
+			}
+			task.Labels = append(task.Labels, label)
+		}
+
+		if name := cell(record, "category"); name != "" {
+			id, ok := categoryIds[strings.ToLower(name)]
+			switch {
+			case ok:
+				task.CategoryID = id
+			case !req.CreateCategories:
+				invalid(row+".category", "category %q does not exist, set create_categories to create it", name)
+			case len(name) > 255:
+				invalid(row+".category", "must be at most 255 characters")
+			default:
+				position := len(missing)
+				for j, missingName := range missing {
+					if missingName == strings.ToLower(name) {
+						position = j
+					}
+				}
+				if position == len(missing) {
+					missing = append(missing, strings.ToLower(name))
+					categories = append(categories, entity.Category{Type: name, UserID: userId})
+				}
+				categoryOf[len(tasks)] = position
+			}
+		} else if req.CategoryID == 0 {
+			invalid(row+".category", "is required without category_id")
+		}
+
+		tasks = append(tasks, task)
+	}
+	if len(fields) > 0 {
+		return entity.TaskCSVImportResult{}, NewValidationError("invalid csv rows", fields)
+	}
+
+	created := categories[len(categories)-len(missing):]
+	err = s.taskRepo.StoreTasksWithCategories(ctx, created, tasks, categoryOf)
+	if err != nil {
+		return entity.TaskCSVImportResult{}, err
+	}
+
+	result := entity.TaskCSVImportResult{TaskIDs: []int{}, CreatedCategories: created}
+	for _, category := range created {
+		s.eventBus.Publish(categoryEvent(entity.EventCategoryCreated, category))
+	}
+	for _, task := range tasks {
+		result.Tasks++
+		result.TaskIDs = append(result.TaskIDs, task.ID)
+		s.eventBus.Publish(taskEvent(entity.EventTaskCreated, userId, task))
+	}
+	return result, nil
+}
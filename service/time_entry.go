@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var (
+	ErrTimerAlreadyRunning = newError(KindConflict, "timer_already_running", "another timer is already running")
+	ErrNoRunningTimer      = newError(KindNotFound, "no_running_timer", "no running timer")
+	ErrTaskNotFound        = newError(KindNotFound, "task_not_found", "task not found")
+	ErrInvalidTimeRange    = newError(KindInvalid, "invalid_time_range", "invalid time range")
+)
+
+type TimeEntryService interface {
+	StartTimer(ctx context.Context, userId, taskId int) (entity.TimeEntry, error)
+	StopTimer(ctx context.Context, userId int) (entity.TimeEntry, error)
+	StoreTimeEntry(ctx context.Context, entry *entity.TimeEntry) (entity.TimeEntry, error)
+	GetTimeReport(ctx context.Context, userId int, filter entity.TimeReportFilter) (entity.TimeReport, error)
+}
+
+type timeEntryService struct {
+	timeEntryRepo repository.TimeEntryRepository
+	taskRepo      repository.TaskRepository
+}
+
+func NewTimeEntryService(timeEntryRepo repository.TimeEntryRepository, taskRepo repository.TaskRepository) TimeEntryService {
+	return &timeEntryService{timeEntryRepo, taskRepo}
+}
+
+func (s *timeEntryService) StartTimer(ctx context.Context, userId, taskId int) (entity.TimeEntry, error) {
+	if err := s.checkTaskOwner(ctx, userId, taskId); err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	running, err := s.timeEntryRepo.GetRunningTimeEntry(ctx, userId)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	if running.ID != 0 {
+		return running, ErrTimerAlreadyRunning
+	}
+
+	entry := entity.TimeEntry{
+		TaskID:    taskId,
+		UserID:    userId,
+		StartedAt: time.Now(),
+	}
+
+	_, err = s.timeEntryRepo.StoreTimeEntry(ctx, &entry)
+	if err != nil {
+		// the partial unique index on running entries rejects a timer
+		// started concurrently by another request
+		running, getErr := s.timeEntryRepo.GetRunningTimeEntry(ctx, userId)
+		if getErr == nil && running.ID != 0 {
+			return running, ErrTimerAlreadyRunning
+		}
+		return entity.TimeEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (s *timeEntryService) StopTimer(ctx context.Context, userId int) (entity.TimeEntry, error) {
+	entry, err := s.timeEntryRepo.GetRunningTimeEntry(ctx, userId)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	if entry.ID == 0 {
+		return entity.TimeEntry{}, ErrNoRunningTimer
+	}
+
+	endedAt := time.Now()
+	entry.EndedAt = &endedAt
+	entry.Duration = int64(endedAt.Sub(entry.StartedAt).Seconds())
+
+	err = s.timeEntryRepo.UpdateTimeEntry(ctx, &entry)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+	return entry, nil
+}
+
+func (s *timeEntryService) StoreTimeEntry(ctx context.Context, entry *entity.TimeEntry) (entity.TimeEntry, error) {
+	if entry.EndedAt == nil || !entry.EndedAt.After(entry.StartedAt) {
+		return entity.TimeEntry{}, ErrInvalidTimeRange
+	}
+
+	if err := s.checkTaskOwner(ctx, entry.UserID, entry.TaskID); err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	entry.Duration = int64(entry.EndedAt.Sub(entry.StartedAt).Seconds())
+
+	_, err := s.timeEntryRepo.StoreTimeEntry(ctx, entry)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+	return *entry, nil
+}
+
+func (s *timeEntryService) GetTimeReport(ctx context.Context, userId int, filter entity.TimeReportFilter) (entity.TimeReport, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return entity.TimeReport{}, ErrInvalidTimeRange
+	}
+
+	items, err := s.timeEntryRepo.GetTimeReport(ctx, userId, filter)
+	if err != nil {
+		return entity.TimeReport{}, err
+	}
+
+	return entity.NewTimeReport(filter, items), nil
+}
+
+func (s *timeEntryService) checkTaskOwner(ctx context.Context, userId, taskId int) error {
+	task, err := s.taskRepo.GetTaskByID(ctx, taskId)
+	if err != nil {
+		return err
+	}
+
+	if task.ID == 0 || task.UserID != userId {
+		return ErrTaskNotFound
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/snykk/kanban-app/entity"
+)
+
+var ErrInvalidTrelloBoard = newError(KindInvalid, "invalid_trello_board", "invalid trello board")
+
+// ImportTrello adds a Trello board export to the user's board. Lists become
+// categories and cards become tasks. Checklists are appended to the task
+// description as Markdown task lists, due dates keep their day and card
+// comments are recreated as written by the importing user.
+// A dry run only reports what would be created.
+func (s *boardService) ImportTrello(ctx context.Context, userId int, board entity.TrelloBoard, dryRun bool) (entity.TrelloImportReport, error) {
+	if len(board.Lists) == 0 {
+		return entity.TrelloImportReport{}, fmt.Errorf("%w: the file has no lists, is it a Trello board export?", ErrInvalidTrelloBoard)
+	}
+
+	export, report := trelloExport(board)
+	report.DryRun = dryRun
+	if dryRun {
+		if _, fields := snapshotOf(export); len(fields) > 0 {
+			return entity.TrelloImportReport{}, NewValidationError("invalid trello board", fields)
+		}
+		return report, nil
+	}
+
+	result, err := s.ImportBoard(ctx, userId, export)
+	if err != nil {
+		return entity.TrelloImportReport{}, err
+	}
+	report.Result = &result
+	return report, nil
+}
+
+// trelloExport converts a Trello board to a board export, the ids of the
+// export are assigned in list and card order.
+func trelloExport(board entity.TrelloBoard) (entity.BoardExport, entity.TrelloImportReport) {
+	export := entity.BoardExport{
+		Version:      entity.BoardExportVersion,
+		ExportedAt:   time.Now().UTC(),
+		Categories:   []entity.ExportCategory{},
+		CustomFields: []entity.ExportCustomField{},
+		Tasks:        []entity.ExportTask{},
+	}
+	report := entity.TrelloImportReport{Categories: []entity.TrelloImportColumn{}, Warnings: []string{}}
+	warn := func(format string, args ...interface{}) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	lists := append([]entity.TrelloList(nil), board.Lists...)
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+
+	categoryIds := map[string]int{}
+	columns := map[string]int{}
+	for _, list := range lists {
+		if list.Closed {
+			warn("list %q is archived and was skipped with its cards", list.Name)
+			continue
+		}
+
+		name := truncateText(strings.TrimSpace(list.Name), 255)
+		if name == "" {
+			name = "Untitled"
+		}
+		categoryIds[list.ID] = len(export.Categories) + 1
+		columns[list.ID] = len(report.Categories)
+		export.Categories = append(export.Categories, entity.ExportCategory{ID: categoryIds[list.ID], Type: name})
+		report.Categories = append(report.Categories, entity.TrelloImportColumn{Name: name, Tasks: []string{}})
+	}
+
+	checklists := map[string][]entity.TrelloChecklist{}
+	for _, checklist := range board.Checklists {
+		checklists[checklist.IDCard] = append(checklists[checklist.IDCard], checklist)
+	}
+
+	comments := map[string][]entity.ExportComment{}
+	for _, action := range board.Actions {
+		if action.Type != "commentCard" || strings.TrimSpace(action.Data.Text) == "" {
+			continue
+		}
+		comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], entity.ExportComment{Body: action.Data.Text, CreatedAt: action.Date})
+	}
+
+	cards := append([]entity.TrelloCard(nil), board.Cards...)
+	sort.SliceStable(cards, func(i, j int) bool { return cards[i].Pos < cards[j].Pos })
+
+	for _, card := range cards {
+		categoryId, ok := categoryIds[card.IDList]
+		if !ok {
+			continue
+		}
+
+		title := strings.TrimSpace(card.Name)
+		if title == "" {
+			title = "Untitled"
+		}
+		if len(title) > 255 {
+			warn("title of card %q was shortened to 255 bytes", truncateText(title, 40))
+			title = truncateText(title, 255)
+		}
+
+		labels := []string{}
+		for _, label := range card.Labels {
+			name := strings.TrimSpace(label.Name)
+			if name == "" {
+				name = label.Color
+			}
+			if name == "" || containsString(labels, name) {
+				continue
+			}
+			labels = append(labels, truncateText(name, entity.MaxLabelLength))
+		}
+
+		description := card.Desc
+		cardChecklists := checklists[card.ID]
+		sort.SliceStable(cardChecklists, func(i, j int) bool { return cardChecklists[i].Pos < cardChecklists[j].Pos })
+		for _, checklist := range cardChecklists {
+			items := append([]entity.TrelloCheckItem(nil), checklist.CheckItems...)
+			sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+
+			lines := []string{"### " + checklist.Name}
+			for _, item := range items {
+				box := "[ ]"
+				if item.State == "complete" {
+					box = "[x]"
+				}
+				lines = append(lines, fmt.Sprintf("- %s %s", box, item.Name))
+			}
+			report.ChecklistItems += len(items)
+
+			if description != "" {
+				description += "\n\n"
+			}
+			description += strings.Join(lines, "\n")
+		}
+
+		updatedAt := card.DateLastActivity
+		if updatedAt.IsZero() {
+			updatedAt = export.ExportedAt
+		}
+		task := entity.ExportTask{
+			ID:          len(export.Tasks) + 1,
+			CategoryID:  categoryId,
+			Title:       title,
+			Description: description,
+			Labels:      labels,
+			CreatedAt:   updatedAt,
+			UpdatedAt:   updatedAt,
+			Comments:    comments[card.ID],
+		}
+		if card.Closed {
+			task.ArchivedAt = &updatedAt
+			report.ArchivedTasks++
+		}
+
+		if card.Due != nil {
+			due := card.Due.UTC()
+			dueDate := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+			task.DueDate = &dueDate
+			report.DueDates++
+		}
+
+		export.Tasks = append(export.Tasks, task)
+		report.Tasks++
+		report.Comments += len(task.Comments)
+		column := &report.Categories[columns[card.IDList]]
+		column.Tasks = append(column.Tasks, title)
+	}
+
+	return export, report
+}
+
+// truncateText shortens s to at most n bytes, the length the validations
+// check, without splitting a character.
+func truncateText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/snykk/kanban-app/entity"
@@ -10,6 +9,12 @@ import (
 	"github.com/snykk/kanban-app/utils"
 )
 
+var (
+	ErrUserNotFound       = newError(KindNotFound, "user_not_found", "user not found")
+	ErrWrongCredentials   = newError(KindUnauthorized, "wrong_credentials", "wrong email or password")
+	ErrEmailAlreadyExists = newError(KindConflict, "email_already_exists", "email already exists")
+)
+
 type UserService interface {
 	Login(ctx context.Context, user *entity.User) (id int, err error)
 	Register(ctx context.Context, user *entity.User) (entity.User, error)
@@ -39,12 +44,10 @@ func (s *userService) Login(ctx context.Context, user *entity.User) (id int, err
 		return 0, err
 	}
 
-	if dbUser.Email == "" || dbUser.ID == 0 {
-		return 0, errors.New("user not found")
-	}
-
-	if !utils.ValidateHash(user.Password, dbUser.Password) {
-		return 0, errors.New("wrong email or password")
+	// an unknown email fails like a wrong password so logins cannot be used
+	// to find out which emails have an account
+	if dbUser.ID == 0 || !utils.ValidateHash(user.Password, dbUser.Password) {
+		return 0, ErrWrongCredentials
 	}
 
 	return dbUser.ID, nil
@@ -57,7 +60,7 @@ func (s *userService) Register(ctx context.Context, user *entity.User) (entity.U
 	}
 
 	if dbUser.Email != "" || dbUser.ID != 0 {
-		return *user, errors.New("email already exists")
+		return *user, ErrEmailAlreadyExists
 	}
 
 	user.Password, err = utils.GenerateHash(user.Password)
@@ -0,0 +1,429 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+	"github.com/snykk/kanban-app/repository"
+)
+
+var (
+	ErrWebhookNotFound  = newError(KindNotFound, "webhook_not_found", "webhook not found")
+	ErrDeliveryNotFound = newError(KindNotFound, "delivery_not_found", "delivery not found")
+	ErrInvalidWebhook   = newError(KindInvalid, "invalid_webhook", "invalid webhook")
+
+	errNonPublicAddress = errors.New("webhook address is not public")
+)
+
+const (
+	webhookQueueSize       = 256
+	webhookBatchSize       = 20
+	webhookPollInterval    = time.Second
+	webhookTimeout         = 10 * time.Second
+	webhookStaleAfter      = time.Minute
+	maxWebhookResponseBody = 1024
+)
+
+// nonPublicNetworks are the ranges a webhook may not reach: loopback,
+// private, link-local (which holds the cloud metadata address), shared,
+// multicast and reserved addresses.
+var nonPublicNetworks = parseCIDRs(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "64:ff9b::/96", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+type WebhookService interface {
+	GetWebhooks(ctx context.Context, userId int) ([]entity.Webhook, error)
+	StoreWebhook(ctx context.Context, webhook *entity.Webhook) (entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, userId int, id int) error
+	GetDeliveries(ctx context.Context, userId int, webhookId int) ([]entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, userId int, webhookId int, deliveryId int) (entity.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhookRepo  repository.WebhookRepository
+	retryBase    time.Duration
+	allowPrivate bool
+	client       *http.Client
+	events       chan entity.BoardEvent
+	wake         chan struct{}
+}
+
+// NewWebhookService listens to the event bus and delivers the events in
+// the background. Deliveries are stored before they are attempted, so
+// retries survive a restart. Unless allowPrivate is set, webhooks only
+// reach public addresses.
+func NewWebhookService(webhookRepo repository.WebhookRepository, eventBus EventBus, retryBase time.Duration, allowPrivate bool) WebhookService {
+	if retryBase <= 0 {
+		retryBase = entity.DefaultWebhookRetryBase
+	}
+
+	s := &webhookService{
+		webhookRepo:  webhookRepo,
+		retryBase:    retryBase,
+		allowPrivate: allowPrivate,
+		client:       newWebhookClient(allowPrivate),
+		events:       make(chan entity.BoardEvent, webhookQueueSize),
+		wake:         make(chan struct{}, 1),
+	}
+
+	eventBus.Listen(s.enqueue)
+	go s.run()
+	return s
+}
+
+func (s *webhookService) GetWebhooks(ctx context.Context, userId int) ([]entity.Webhook, error) {
+	return s.webhookRepo.GetWebhooksByUserID(ctx, userId)
+}
+
+func (s *webhookService) StoreWebhook(ctx context.Context, webhook *entity.Webhook) (entity.Webhook, error) {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return entity.Webhook{}, fmt.Errorf("%w: url must be an absolute http or https url", ErrInvalidWebhook)
+	}
+
+	// names are checked when they are dialed, as they may resolve elsewhere by then
+	if ip := net.ParseIP(target.Hostname()); ip != nil && !s.allowPrivate && !isPublicIP(ip) {
+		return entity.Webhook{}, fmt.Errorf("%w: url must point to a public address", ErrInvalidWebhook)
+	}
+
+	events := make([]string, 0, len(webhook.Events))
+	seen := map[string]bool{}
+	for _, eventType := range webhook.Events {
+		if !isWebhookEventType(eventType) {
+			return entity.Webhook{}, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, eventType)
+		}
+
+		if !seen[eventType] {
+			seen[eventType] = true
+			events = append(events, eventType)
+		}
+	}
+	webhook.Events = events
+
+	err = s.webhookRepo.StoreWebhook(ctx, webhook)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+	return *webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, userId int, id int) error {
+	_, err := s.getWebhook(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return s.webhookRepo.DeleteWebhook(ctx, id)
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, userId int, webhookId int) ([]entity.WebhookDelivery, error) {
+	_, err := s.getWebhook(ctx, userId, webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.GetDeliveriesByWebhookID(ctx, webhookId)
+}
+
+// Redeliver sends the payload of a past delivery again as a new delivery.
+// A webhookId of 0 skips checking which webhook the delivery belongs to.
+func (s *webhookService) Redeliver(ctx context.Context, userId int, webhookId int, deliveryId int) (entity.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.GetDeliveryByID(ctx, deliveryId)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	if delivery.ID == 0 || (webhookId != 0 && delivery.WebhookID != webhookId) {
+		return entity.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+
+	_, err = s.getWebhook(ctx, userId, delivery.WebhookID)
+	if errors.Is(err, ErrWebhookNotFound) {
+		return entity.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	redelivery := []entity.WebhookDelivery{{
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        entity.DeliveryPending,
+		RedeliveryOf:  &delivery.ID,
+		NextAttemptAt: &now,
+	}}
+
+	err = s.webhookRepo.StoreDeliveries(ctx, redelivery)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	s.notify()
+	return redelivery[0], nil
+}
+
+func (s *webhookService) getWebhook(ctx context.Context, userId int, id int) (entity.Webhook, error) {
+	webhook, err := s.webhookRepo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	if webhook.ID == 0 || webhook.UserID != userId {
+		return entity.Webhook{}, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) enqueue(event entity.BoardEvent) {
+	select {
+	case s.events <- event:
+	default:
+		log.Println("webhook queue full, dropped event", event.ID)
+	}
+}
+
+func (s *webhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *webhookService) run() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-s.events:
+			s.record(event)
+		case <-s.wake:
+		case <-ticker.C:
+		}
+
+		s.deliverDue()
+	}
+}
+
+// record stores a pending delivery of the event for every webhook of the
+// board subscribed to it.
+func (s *webhookService) record(event entity.BoardEvent) {
+	ctx := context.Background()
+
+	webhooks, err := s.webhookRepo.GetWebhooksByUserID(ctx, event.BoardID)
+	if err != nil {
+		log.Println("get webhooks:", err.Error())
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Println("encode webhook payload:", err.Error())
+		return
+	}
+
+	now := time.Now()
+	var deliveries []entity.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        entity.DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+
+	err = s.webhookRepo.StoreDeliveries(ctx, deliveries)
+	if err != nil {
+		log.Println("store webhook deliveries:", err.Error())
+	}
+}
+
+func (s *webhookService) deliverDue() {
+	now := time.Now()
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(context.Background(), now, now.Add(-webhookStaleAfter), webhookBatchSize)
+	if err != nil {
+		log.Println("claim webhook deliveries:", err.Error())
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *entity.WebhookDelivery) {
+			defer wg.Done()
+			s.attempt(delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+}
+
+// attempt posts the delivery once and records the outcome. Failed
+// attempts are retried with exponential backoff until MaxWebhookAttempts.
+func (s *webhookService) attempt(delivery *entity.WebhookDelivery) {
+	ctx := context.Background()
+
+	webhook, err := s.webhookRepo.GetWebhookByID(ctx, delivery.WebhookID)
+	if err != nil {
+		log.Println("get webhook:", err.Error())
+		return
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode = 0
+	delivery.Error = ""
+
+	if webhook.ID == 0 {
+		delivery.Error = "webhook was deleted"
+	} else {
+		delivery.StatusCode, err = s.post(ctx, webhook, *delivery)
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+	}
+
+	now := time.Now()
+	switch {
+	case delivery.StatusCode >= 200 && delivery.StatusCode < 300:
+		delivery.Status = entity.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case webhook.ID == 0 || delivery.Attempts >= entity.MaxWebhookAttempts:
+		delivery.Status = entity.DeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(s.retryBase << (delivery.Attempts - 1))
+		delivery.Status = entity.DeliveryPending
+		delivery.NextAttemptAt = &next
+	}
+
+	err = s.webhookRepo.UpdateDelivery(ctx, delivery)
+	if err != nil {
+		log.Println("update webhook delivery:", err.Error())
+	}
+}
+
+// post sends the delivery and returns the status code of the response. The
+// body is drained for the connection's sake but not kept, the log must not
+// become a way to read what a webhook URL answers.
+func (s *webhookService) post(ctx context.Context, webhook entity.Webhook, delivery entity.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kanban-app-webhooks")
+	req.Header.Set(entity.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(entity.WebhookDeliveryHeader, fmt.Sprint(delivery.ID))
+	req.Header.Set(entity.WebhookSignatureHeader, signWebhookPayload(webhook.Secret, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxWebhookResponseBody))
+	return resp.StatusCode, nil
+}
+
+// newWebhookClient dials without a proxy and, unless allowPrivate is set,
+// refuses non-public addresses. The check runs on the address actually
+// dialed, after name resolution and for every redirect, so a name that
+// later resolves to an internal address cannot get around it.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errNonPublicAddress
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        webhookBatchSize,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func isWebhookEventType(eventType string) bool {
+	if eventType == entity.WebhookAllEvents {
+		return true
+	}
+
+	for _, known := range entity.BoardEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidETag = errors.New("invalid entity tag")
+	ErrWeakETag    = errors.New("weak entity tag")
+)
+
+// ETag formats a row version as a strong entity tag.
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseETag reads the version out of an If-Match value. An empty value or
+// "*" matches any version and yields 0. If-Match compares tags strongly
+// (RFC 7232, section 3.1), so a weak tag never matches and yields
+// ErrWeakETag.
+func ParseETag(tag string) (int, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || tag == "*" {
+		return 0, nil
+	}
+
+	if strings.HasPrefix(tag, "W/") {
+		return 0, ErrWeakETag
+	}
+
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, ErrInvalidETag
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return 0, ErrInvalidETag
+	}
+	return version, nil
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MentionPattern matches @email and @handle tokens that are not part of a
+// word or an email address themselves.
+var MentionPattern = regexp.MustCompile(`(^|[^\w@.])@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}|[A-Za-z0-9._-]+)`)
+
+// ParseMentions returns the unique mention tokens in text, without the
+// leading @.
+func ParseMentions(text string) []string {
+	var tokens []string
+	seen := map[string]bool{}
+
+	for _, match := range MentionPattern.FindAllStringSubmatch(text, -1) {
+		token := strings.TrimRight(match[2], ".")
+		if token == "" || seen[token] {
+			continue
+		}
+
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/snykk/kanban-app/entity"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf describes a Go value as an OpenAPI schema. Named structs are
+// added to schemas once and referenced from there; their `json` tags name
+// the properties and their `binding` tags add the constraints Validate
+// enforces.
+func SchemaOf(v interface{}, schemas map[string]*entity.Schema) *entity.Schema {
+	return schemaOf(reflect.TypeOf(v), schemas)
+}
+
+func schemaOf(t reflect.Type, schemas map[string]*entity.Schema) *entity.Schema {
+	if t == nil {
+		return &entity.Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := *schemaOf(t.Elem(), schemas)
+		if schema.Ref != "" {
+			return &schema
+		}
+		schema.Nullable = true
+		return &schema
+	case reflect.Bool:
+		return &entity.Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &entity.Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &entity.Schema{Type: "number"}
+	case reflect.String:
+		return &entity.Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &entity.Schema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return &entity.Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t == timeType {
+			return &entity.Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			// reserve the name first so recursive types terminate
+			schemas[t.Name()] = &entity.Schema{}
+			*schemas[t.Name()] = *structSchema(t, schemas)
+		}
+		return &entity.Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+
+	// interface{} and the like accept any value
+	return &entity.Schema{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]*entity.Schema) *entity.Schema {
+	schema := &entity.Schema{Type: "object", Properties: map[string]*entity.Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		name := jsonName(field)
+		property := schemaOf(field.Type, schemas)
+		for _, rule := range bindingRules(field) {
+			switch rule.Name {
+			case "required":
+				schema.Required = append(schema.Required, name)
+			case "email":
+				property.Format = "email"
+			case "min", "max":
+				applyLimit(property, rule.Name, ruleLimit(rule))
+			case "maxbytes":
+				// JSON Schema counts characters, a byte bound is at most as many
+				applyLimit(property, "max", ruleLimit(rule))
+			}
+		}
+
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+func applyLimit(schema *entity.Schema, rule string, limit float64) {
+	count := int(limit)
+
+	switch schema.Type {
+	case "string":
+		if rule == "min" {
+			schema.MinLength = &count
+		} else {
+			schema.MaxLength = &count
+		}
+	case "array":
+		if rule == "min" {
+			schema.MinItems = &count
+		} else {
+			schema.MaxItems = &count
+		}
+	case "integer", "number":
+		if rule == "min" {
+			schema.Minimum = &limit
+		} else {
+			schema.Maximum = &limit
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/snykk/kanban-app/entity"
+)
+
+// WriteProblem writes the problem as application/problem+json.
+func WriteProblem(w http.ResponseWriter, problem entity.Problem) {
+	w.Header().Set("Content-Type", entity.ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// WriteError writes a problem with the code derived from the status.
+func WriteError(w http.ResponseWriter, status int, detail string) {
+	WriteProblem(w, entity.NewProblem(status, detail))
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/snykk/kanban-app/entity"
+)
+
+// Validate checks a request struct against its `binding` tags and returns
+// one error per failing field, in field order. Supported rules:
+//
+//	required   the value is not empty (blank strings count as empty)
+//	omitempty  skip the other rules when the value is empty
+//	min=N      the length of a string or slice, or a number, is at least N
+//	max=N      the length of a string or slice, or a number, is at most N
+//	maxbytes=N the UTF-8 encoding of a string is at most N bytes
+//	email      the value is a plain email address
+//	estimate   the number is a valid task estimate, see entity.MaxTaskEstimate
+func Validate(req interface{}) []entity.FieldError {
+	value := reflect.Indirect(reflect.ValueOf(req))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []entity.FieldError
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		rules := bindingRules(field)
+		if len(rules) == 0 {
+			continue
+		}
+
+		message := validateField(value.Field(i), rules)
+		if message != "" {
+			fields = append(fields, entity.FieldError{Field: jsonName(field), Message: message})
+		}
+	}
+	return fields
+}
+
+type bindingRule struct {
+	Name string
+	Arg  string
+}
+
+func bindingRules(field reflect.StructField) []bindingRule {
+	tag := field.Tag.Get("binding")
+	if tag == "" {
+		return nil
+	}
+
+	var rules []bindingRule
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+		rules = append(rules, bindingRule{name, arg})
+	}
+	return rules
+}
+
+// ruleLimit reads the bound of a min or max rule.
+func ruleLimit(rule bindingRule) float64 {
+	limit, err := strconv.ParseFloat(rule.Arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: invalid %s rule %q", rule.Name, rule.Arg))
+	}
+	return limit
+}
+
+func validateField(value reflect.Value, rules []bindingRule) string {
+	var empty bool
+	switch value.Kind() {
+	case reflect.String:
+		empty = strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		empty = value.Len() == 0
+	default:
+		empty = value.IsZero()
+	}
+
+	for _, rule := range rules {
+		switch rule.Name {
+		case "required":
+			if empty {
+				return "is required"
+			}
+		case "omitempty":
+			if empty {
+				return ""
+			}
+		case "min", "max":
+			if message := checkLimit(value, rule.Name, ruleLimit(rule)); message != "" {
+				return message
+			}
+		case "maxbytes":
+			if limit := ruleLimit(rule); float64(len(value.String())) > limit {
+				return "must be at most " + rule.Arg + " bytes"
+			}
+		case "email":
+			address, err := mail.ParseAddress(value.String())
+			if err != nil || address.Address != value.String() {
+				return "must be a valid email address"
+			}
+		case "estimate":
+			if !entity.IsValidEstimate(value.Float()) {
+				return fmt.Sprintf("must be a number from 0 to %d", entity.MaxTaskEstimate)
+			}
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q", rule.Name))
+		}
+	}
+	return ""
+}
+
+func checkLimit(value reflect.Value, rule string, limit float64) string {
+	var size float64
+	var unit string
+
+	switch value.Kind() {
+	case reflect.String:
+		size, unit = float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		size, unit = float64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = value.Float()
+	default:
+		return ""
+	}
+
+	bound := strconv.FormatFloat(limit, 'f', -1, 64)
+	if rule == "min" && size < limit {
+		return "must be at least " + bound + unit
+	}
+	if rule == "max" && size > limit {
+		return "must be at most " + bound + unit
+	}
+	return ""
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}